@@ -1,6 +1,18 @@
 package cost
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/cmd/flags"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/output"
+	outputCost "github.com/kaytu-io/pennywise/pkg/output/cost"
+	"github.com/kaytu-io/pennywise/pkg/pricing"
+	"github.com/kaytu-io/pennywise/pkg/recommendation"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
 
 // CostCmd cost commands
 var CostCmd = &cobra.Command{
@@ -14,14 +26,310 @@ var CostCmd = &cobra.Command{
 
 func init() {
 	CostCmd.AddCommand(projectCommand)
-	projectCommand.Flags().String("json-path", "", "terraform plan json file path")
+	projectCommand.Flags().String("plan-path", "", "terraform plan file path, the json of terraform show -json or the binary plan, which needs terraform or tofu (see --terraform-binary)")
+	projectCommand.Flags().String("json-path", "", "alias of --plan-path, accepts binary plans too")
+	projectCommand.Flags().String("terraform-binary", "", "terraform or tofu binary to read binary plans with, PENNYWISE_TERRAFORM_BINARY or the one in the PATH by default")
 	projectCommand.Flags().String("project-path", ".", "path to terraform project")
 	projectCommand.Flags().StringSlice("terraform-var-file", []string{}, "path to terraform variables file")
 	projectCommand.Flags().String("usage", "", "usage file path")
 	projectCommand.Flags().Bool("classic", false, "Show results in classic view (not interactive)")
+	projectCommand.Flags().String("output", output.Interactive, "output format (json | html), interactive view by default, the html report path can be given as an argument")
+	projectCommand.Flags().Int("chunk-size", server.DefaultChunkSize, "maximum number of resources priced in a single request")
+	projectCommand.Flags().Int("concurrency", server.DefaultConcurrency, "maximum number of pricing requests sent at the same time")
+	projectCommand.Flags().StringSlice("group-by-tag", []string{}, "tag keys to allocate the costs by (e.g. team,env), resources without the tag are reported as untagged")
+	projectCommand.Flags().StringSlice("require-tags", []string{}, "tag keys required on every resource with a cost, fails if any of them is missing")
+	projectCommand.Flags().String("period", cost.PeriodMonthly, "period of the shown costs (hourly | daily | monthly | yearly)")
+	projectCommand.Flags().Int("months", 0, "show the total cost over the given number of months instead of the period")
+	projectCommand.Flags().String("cost-view", cost.ViewAmortized, "how upfront payments are included in the costs (amortized | cash-flow)")
+	projectCommand.Flags().String("currency", "", "currency to convert the costs to (e.g. EUR), using the rates of the exchange rates file")
+	projectCommand.Flags().String("exchange-rates", "", "path to the exchange rates file (json | yaml), PENNYWISE_EXCHANGE_RATES by default")
+	projectCommand.Flags().Bool("compare-pricing", false, "compare the costs of the compute resources with on-demand, reserved and spot purchase options")
+	projectCommand.Flags().Bool("recommend", false, "suggest cheaper alternatives of the resources (e.g. graviton instances, gp3 volumes) with their estimated savings")
+	projectCommand.Flags().String("recommendation-rules", "", "path to a file of recommendation rules (json | yaml) added to the built-in ones, PENNYWISE_RECOMMENDATION_RULES by default")
+	projectCommand.Flags().Bool("fail-on-missing-prices", false, "fail if the price of any component could not be found")
+
+	CostCmd.AddCommand(stateCommand)
+	stateCommand.Flags().String("state-path", "terraform.tfstate", "terraform state file path, or the output of terraform show -json")
+	stateCommand.Flags().String("region", "", "region of the resources without one in the state, AWS_REGION or AWS_DEFAULT_REGION by default")
+	stateCommand.Flags().String("usage", "", "usage file path")
+	stateCommand.Flags().Bool("classic", false, "Show results in classic view (not interactive)")
+	stateCommand.Flags().String("output", output.Interactive, "output format (json | html), interactive view by default, the html report path can be given as an argument")
+	stateCommand.Flags().Int("chunk-size", server.DefaultChunkSize, "maximum number of resources priced in a single request")
+	stateCommand.Flags().Int("concurrency", server.DefaultConcurrency, "maximum number of pricing requests sent at the same time")
+	stateCommand.Flags().StringSlice("group-by-tag", []string{}, "tag keys to allocate the costs by (e.g. team,env), resources without the tag are reported as untagged")
+	stateCommand.Flags().StringSlice("require-tags", []string{}, "tag keys required on every resource with a cost, fails if any of them is missing")
+	stateCommand.Flags().String("period", cost.PeriodMonthly, "period of the shown costs (hourly | daily | monthly | yearly)")
+	stateCommand.Flags().Int("months", 0, "show the total cost over the given number of months instead of the period")
+	stateCommand.Flags().String("cost-view", cost.ViewAmortized, "how upfront payments are included in the costs (amortized | cash-flow)")
+	stateCommand.Flags().String("currency", "", "currency to convert the costs to (e.g. EUR), using the rates of the exchange rates file")
+	stateCommand.Flags().String("exchange-rates", "", "path to the exchange rates file (json | yaml), PENNYWISE_EXCHANGE_RATES by default")
+	stateCommand.Flags().Bool("fail-on-missing-prices", false, "fail if the price of any component could not be found")
 
 	CostCmd.AddCommand(submissionCommand)
 	submissionCommand.Flags().String("submission-id", "", "submission id")
 	submissionCommand.MarkFlagRequired("submission-id")
 	submissionCommand.Flags().Bool("classic", false, "Show results in classic view (not interactive)")
+	submissionCommand.Flags().String("output", output.Interactive, "output format (json | html), interactive view by default, the html report path can be given as an argument")
+	submissionCommand.Flags().Int("chunk-size", server.DefaultChunkSize, "maximum number of resources priced in a single request")
+	submissionCommand.Flags().Int("concurrency", server.DefaultConcurrency, "maximum number of pricing requests sent at the same time")
+	submissionCommand.Flags().StringSlice("group-by-tag", []string{}, "tag keys to allocate the costs by (e.g. team,env), resources without the tag are reported as untagged")
+	submissionCommand.Flags().StringSlice("require-tags", []string{}, "tag keys required on every resource with a cost, fails if any of them is missing")
+	submissionCommand.Flags().String("period", cost.PeriodMonthly, "period of the shown costs (hourly | daily | monthly | yearly)")
+	submissionCommand.Flags().Int("months", 0, "show the total cost over the given number of months instead of the period")
+	submissionCommand.Flags().String("cost-view", cost.ViewAmortized, "how upfront payments are included in the costs (amortized | cash-flow)")
+	submissionCommand.Flags().String("currency", "", "currency to convert the costs to (e.g. EUR), using the rates of the exchange rates file")
+	submissionCommand.Flags().String("exchange-rates", "", "path to the exchange rates file (json | yaml), PENNYWISE_EXCHANGE_RATES by default")
+	submissionCommand.Flags().Bool("fail-on-missing-prices", false, "fail if the price of any component could not be found")
+}
+
+// outputOptions defines how the costs are shown
+type outputOptions struct {
+	format  string
+	classic bool
+	// file is the path of the html report
+	file      string
+	groupings []cost.Grouping
+	// requiredTags are the tags every resource with a cost must have
+	requiredTags []string
+	period       cost.Period
+	currency     string
+	// rates are the exchange rates to convert the costs to the currency, nil if no currency is set
+	rates *cost.ExchangeRates
+	// failOnMissingPrices fails the command if any component could not be priced
+	failOnMissingPrices bool
+	// comparePricing compares the costs of the compute resources with their purchase options
+	comparePricing bool
+	// pricedStates are the compute resources priced with each purchase option, keyed by the option
+	pricedStates map[string]*cost.ModularState
+	// comparison is the comparison of the purchase options built from the priced states
+	comparison *pricing.Comparison
+	// recommend suggests cheaper alternatives of the resources
+	recommend bool
+	// recommendationRules are the built-in rules and the ones of the rules file the resources are checked against
+	recommendationRules recommendation.RuleSet
+	// recommendedStates are the resources priced with the values suggested by each rule, keyed by the rule name
+	recommendedStates map[string]*cost.ModularState
+	// candidates are the resources changed by the rules that are priced in the recommended states
+	candidates []recommendation.Candidate
+	// recommendations are the suggested alternatives cheaper than the current resources
+	recommendations *recommendation.Report
+}
+
+// readOutputOptions reads the flags defining the output format, the period, the view of the upfront costs,
+// the currency and the cost groupings, the first argument is the path of the html report
+func readOutputOptions(cmd *cobra.Command, args []string) (outputOptions, error) {
+	var file string
+	if len(args) > 0 {
+		file = args[0]
+	}
+	period, err := cost.NewPeriod(flags.ReadStringFlag(cmd, "period"), int(flags.ReadInt64Flag(cmd, "months")))
+	if err != nil {
+		return outputOptions{}, err
+	}
+	period, err = period.WithView(flags.ReadStringFlag(cmd, "cost-view"))
+	if err != nil {
+		return outputOptions{}, err
+	}
+	currency := strings.ToUpper(flags.ReadStringFlag(cmd, "currency"))
+	rates, err := cost.LoadCurrencyRates(currency, flags.ReadStringFlag(cmd, "exchange-rates"))
+	if err != nil {
+		return outputOptions{}, err
+	}
+	return outputOptions{
+		period:       period,
+		currency:     currency,
+		rates:        rates,
+		file:         file,
+		format:       flags.ReadStringFlag(cmd, "output"),
+		classic:      flags.ReadBooleanFlag(cmd, "classic"),
+		groupings:    cost.Groupings(flags.ReadStringArrayFlag(cmd, "group-by-tag")),
+		requiredTags: flags.ReadStringArrayFlag(cmd, "require-tags"),
+
+		failOnMissingPrices: flags.ReadBooleanFlag(cmd, "fail-on-missing-prices"),
+	}, nil
+}
+
+// readChunkOptions reads the flags defining how big submissions are split into pricing requests
+func readChunkOptions(cmd *cobra.Command) (server.ChunkOptions, error) {
+	chunkSize, err := cmd.Flags().GetInt("chunk-size")
+	if err != nil {
+		return server.ChunkOptions{}, err
+	}
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return server.ChunkOptions{}, err
+	}
+	return server.ChunkOptions{
+		ChunkSize:   chunkSize,
+		Concurrency: concurrency,
+		Progress:    printProgress,
+	}, nil
+}
+
+// printProgress shows the number of priced chunks on stderr so it doesn't mix with the results
+func printProgress(done, total int) {
+	fmt.Fprintf(os.Stderr, "\rpricing resources: %d/%d chunks", done, total)
+	if done == total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// viewOptions returns the options of the interactive and structured outputs
+func (o outputOptions) viewOptions() outputCost.Options {
+	return outputCost.Options{
+		Groupings: o.groupings,
+		Period:    o.period,
+		Currency:  o.currency,
+
+		Comparison:      o.comparison,
+		Recommendations: o.recommendations,
+	}
+}
+
+// showCost shows the costs in the requested output format and checks the required tags and the missing prices,
+// the classic view is used instead of the interactive view if the terminal is not interactive
+func showCost(opts outputOptions, state *cost.ModularState) error {
+	if opts.rates != nil {
+		err := state.ConvertCurrency(opts.rates, opts.currency)
+		if err != nil {
+			return err
+		}
+		for _, pricedState := range opts.pricedStates {
+			err = pricedState.ConvertCurrency(opts.rates, opts.currency)
+			if err != nil {
+				return err
+			}
+		}
+		for _, recommendedState := range opts.recommendedStates {
+			err = recommendedState.ConvertCurrency(opts.rates, opts.currency)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if opts.comparePricing {
+		comparison, err := pricing.Compare(state, opts.pricedStates, opts.period)
+		if err != nil {
+			return err
+		}
+		if comparison.Currency == "" {
+			comparison.Currency = opts.currency
+		}
+		opts.comparison = &comparison
+	}
+	if opts.recommend {
+		report, err := opts.recommendationRules.Recommend(state, opts.candidates, opts.recommendedStates, opts.period)
+		if err != nil {
+			return err
+		}
+		if report.Currency == "" {
+			report.Currency = opts.currency
+		}
+		opts.recommendations = &report
+	}
+	err := printCost(opts, state)
+	if err != nil {
+		return err
+	}
+	err = checkRequiredTags(opts.requiredTags, state)
+	if err != nil {
+		return err
+	}
+	if opts.failOnMissingPrices {
+		return checkMissingPrices(state)
+	}
+	return nil
+}
+
+func printCost(opts outputOptions, state *cost.ModularState) error {
+	switch opts.format {
+	case output.Interactive:
+		if opts.classic || !output.IsInteractive() {
+			costString, err := state.ToClassicState().CostString(opts.period)
+			if err != nil {
+				return err
+			}
+			fmt.Println(costString)
+			var tagGroupings []cost.Grouping
+			for _, g := range opts.groupings {
+				if g.By == cost.GroupByTag {
+					tagGroupings = append(tagGroupings, g)
+				}
+			}
+			if len(tagGroupings) > 0 {
+				groupsString, err := state.GroupsCostString(tagGroupings, opts.period)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("\n%s\n\n", groupsString)
+			}
+			if opts.comparison != nil {
+				fmt.Printf("\n%s\n\n", opts.comparison.String())
+			}
+			if opts.recommendations != nil {
+				fmt.Printf("\n%s\n\n", opts.recommendations.String())
+			}
+			fmt.Println("To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md")
+			return nil
+		}
+		err := outputCost.ShowStateCosts(state, opts.viewOptions())
+		if err != nil {
+			return err
+		}
+		if opts.comparison != nil {
+			fmt.Println(opts.comparison.String())
+		}
+		if opts.recommendations != nil {
+			fmt.Println(opts.recommendations.String())
+		}
+		return nil
+	case output.JSON:
+		jsonString, err := outputCost.JSONString(state, opts.viewOptions())
+		if err != nil {
+			return err
+		}
+		fmt.Println(jsonString)
+		return nil
+	case output.HTML:
+		page, err := outputCost.HTMLString(state, opts.viewOptions())
+		if err != nil {
+			return err
+		}
+		path, err := output.WriteHTMLReport(opts.file, page)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("report is written to %s\n", path)
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %s", opts.format)
+	}
+}
+
+// checkRequiredTags fails if any resource with a cost is missing one of the required tags,
+// the resources are listed on stderr so they don't mix with the results
+func checkRequiredTags(requiredTags []string, state *cost.ModularState) error {
+	if len(requiredTags) == 0 {
+		return nil
+	}
+	violations, err := state.MissingTags(requiredTags)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, cost.TagViolationsString(violations))
+	return fmt.Errorf("%d resources are missing required tags %v", len(violations), requiredTags)
+}
+
+// checkMissingPrices fails if the price of any component could not be found,
+// the components are listed on stderr so they don't mix with the results
+func checkMissingPrices(state *cost.ModularState) error {
+	unpriced := state.UnpricedComponents()
+	if len(unpriced) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, cost.UnpricedString(unpriced))
+	return cost.UnpricedError(len(unpriced))
 }
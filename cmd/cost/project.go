@@ -1,22 +1,19 @@
 package cost
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/kaytu-io/infracost/external/providers"
 	"github.com/kaytu-io/pennywise/cmd/cost/terraform"
 	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/pkg"
 	"github.com/kaytu-io/pennywise/pkg/cost"
-	outputCost "github.com/kaytu-io/pennywise/pkg/output/cost"
 	"github.com/kaytu-io/pennywise/pkg/parser/hcl"
+	"github.com/kaytu-io/pennywise/pkg/pricing"
+	"github.com/kaytu-io/pennywise/pkg/recommendation"
 	"github.com/kaytu-io/pennywise/pkg/schema"
 	"github.com/kaytu-io/pennywise/pkg/server"
 	usagePackage "github.com/kaytu-io/pennywise/pkg/usage"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
-	"os"
-	"path/filepath"
 )
 
 var projectCommand = &cobra.Command{
@@ -24,44 +21,39 @@ var projectCommand = &cobra.Command{
 	Short: `Shows the costs by parsing a project resources.`,
 	Long:  `Shows the costs by parsing a project resources.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		usagePath := flags.ReadStringOptionalFlag(cmd, "usage")
-		var usage usagePackage.Usage
-		if usagePath != nil {
-			usageFile, err := os.Open(*usagePath)
-			if err != nil {
-				return fmt.Errorf("error while reading usage file %s", err)
-			}
-			defer usageFile.Close()
+		usage, err := flags.ReadUsage(cmd)
+		if err != nil {
+			return err
+		}
 
-			ext := filepath.Ext(*usagePath)
-			switch ext {
-			case ".json":
-				err = json.NewDecoder(usageFile).Decode(&usage)
-			case ".yaml", ".yml":
-				err = yaml.NewDecoder(usageFile).Decode(&usage)
-			default:
-				return fmt.Errorf("unsupported file format %s for usage file", ext)
-			}
+		opts, err := readOutputOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+		opts.comparePricing = flags.ReadBooleanFlag(cmd, "compare-pricing")
+		opts.recommend = flags.ReadBooleanFlag(cmd, "recommend")
+		if opts.recommend {
+			opts.recommendationRules, err = recommendation.LoadRuleSet(flags.ReadStringFlag(cmd, "recommendation-rules"))
 			if err != nil {
-				return fmt.Errorf("error while parsing usage file %s", err)
+				return err
 			}
-
-		} else {
-			usage = usagePackage.Usage{}
 		}
 
-		classic := flags.ReadBooleanFlag(cmd, "classic")
+		chunkOptions, err := readChunkOptions(cmd)
+		if err != nil {
+			return err
+		}
 
-		jsonPath := flags.ReadStringOptionalFlag(cmd, "json-path")
+		planPath := flags.ReadPlanPathFlag(cmd)
 		projectPath := flags.ReadStringFlag(cmd, "project-path")
 		tfVarFiles := flags.ReadStringArrayFlag(cmd, "terraform-var-file")
-		if jsonPath != nil {
-			err := estimateTfPlanJson(classic, *jsonPath, usage, pkg.DefaultServerAddress)
+		if planPath != nil {
+			err := estimateTfPlanJson(opts, *planPath, flags.ReadStringFlag(cmd, "terraform-binary"), usage, pkg.DefaultServerAddress, chunkOptions)
 			if err != nil {
 				return err
 			}
 		} else {
-			err := estimateTerraformProject(classic, projectPath, usage, pkg.DefaultServerAddress, tfVarFiles)
+			err := estimateTerraformProject(opts, projectPath, usage, pkg.DefaultServerAddress, tfVarFiles, chunkOptions)
 			if err != nil {
 				return err
 			}
@@ -70,15 +62,21 @@ var projectCommand = &cobra.Command{
 	},
 }
 
-func estimateTfPlanJson(classic bool, jsonPath string, usage usagePackage.Usage, ServerClientAddress string) error {
-	file, err := os.Open(jsonPath)
+func estimateTfPlanJson(opts outputOptions, planPath string, terraformBinary string, usage usagePackage.Usage, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
+	file, err := terraform.OpenPlan(planPath, terraformBinary)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 	resources, err := terraform.ParseTerraformPlanJson(file, usage)
 	if err != nil {
 		return err
 	}
+	return estimateResources(opts, resources, ServerClientAddress, chunkOptions)
+}
+
+// estimateResources prices the resources read from a plan or a state and shows their costs
+func estimateResources(opts outputOptions, resources []schema.ResourceDef, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
 	serverClient, err := server.NewPennywiseServerClient(ServerClientAddress)
 	if err != nil {
 		return err
@@ -91,30 +89,30 @@ func estimateTfPlanJson(classic bool, jsonPath string, usage usagePackage.Usage,
 	if err != nil {
 		return err
 	}
-	state, err := serverClient.GetStateCost(*sub)
+	state, err := server.GetStateCostChunked(serverClient, *sub, chunkOptions)
 	if err != nil {
 		return err
 	}
-	if classic {
-		costString, err := state.CostString()
+	modularState := cost.ModularState{
+		Resources: state.Resources,
+	}
+	modularState.SetAttributes(sub.ResourceAttributes())
+	if opts.comparePricing {
+		opts.pricedStates, err = pricePurchaseOptions(serverClient, resources, chunkOptions)
 		if err != nil {
 			return err
 		}
-		fmt.Println(costString)
-		fmt.Println("To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md")
-	} else {
-		modularState := cost.ModularState{
-			Resources: state.Resources,
-		}
-		err = outputCost.ShowStateCosts(&modularState)
+	}
+	if opts.recommend {
+		opts.recommendedStates, opts.candidates, err = priceRecommendations(serverClient, resources, opts.recommendationRules.Rules, chunkOptions)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return showCost(opts, &modularState)
 }
 
-func estimateTerraformProject(classic bool, projectPath string, usage usagePackage.Usage, ServerClientAddress string, tfVarFiles []string) error {
+func estimateTerraformProject(opts outputOptions, projectPath string, usage usagePackage.Usage, ServerClientAddress string, tfVarFiles []string, chunkOptions server.ChunkOptions) error {
 	var projects *schema.ModuleDef
 	var err error
 	if providers.IsTerragruntNestedDir(projectPath, 5) {
@@ -138,23 +136,110 @@ func estimateTerraformProject(classic bool, projectPath string, usage usagePacka
 	if err != nil {
 		return err
 	}
-	state, err := serverClient.GetStateCostV2(*sub)
+	state, err := server.GetStateCostV2Chunked(serverClient, *sub, chunkOptions)
 	if err != nil {
 		return err
 	}
-	if classic {
-		costString, err := state.ToClassicState().CostString()
+	state.SetAttributes(sub.ResourceAttributes())
+	if opts.comparePricing {
+		opts.pricedStates, err = pricePurchaseOptionsV2(serverClient, *projects, chunkOptions)
 		if err != nil {
 			return err
 		}
-		fmt.Println(costString)
-		fmt.Println("To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md")
-	} else {
-		err = outputCost.ShowStateCosts(state)
+	}
+	if opts.recommend {
+		opts.recommendedStates, opts.candidates, err = priceRecommendationsV2(serverClient, *projects, opts.recommendationRules.Rules, chunkOptions)
 		if err != nil {
 			return err
 		}
 	}
+	return showCost(opts, state)
+}
+
+// pricePurchaseOptions prices the compute resources with each of the purchase options
+func pricePurchaseOptions(serverClient server.ServerClient, resources []schema.ResourceDef, chunkOptions server.ChunkOptions) (map[string]*cost.ModularState, error) {
+	states := make(map[string]*cost.ModularState)
+	for _, name := range pricing.OptionNames {
+		optionResources := pricing.Resources(resources, name)
+		if len(optionResources) == 0 {
+			continue
+		}
+		sub, err := schema.CreateSubmission(optionResources)
+		if err != nil {
+			return nil, err
+		}
+		state, err := server.GetStateCostChunked(serverClient, *sub, chunkOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s cost of resources: %w", name, err)
+		}
+		states[name] = &cost.ModularState{Resources: state.Resources}
+	}
+	return states, nil
+}
+
+// pricePurchaseOptionsV2 prices the compute resources of the module with each of the purchase options
+func pricePurchaseOptionsV2(serverClient server.ServerClient, module schema.ModuleDef, chunkOptions server.ChunkOptions) (map[string]*cost.ModularState, error) {
+	states := make(map[string]*cost.ModularState)
+	for _, name := range pricing.OptionNames {
+		optionModule, count := pricing.Module(module, name)
+		if count == 0 {
+			continue
+		}
+		sub, err := schema.CreateSubmissionV2(optionModule)
+		if err != nil {
+			return nil, err
+		}
+		state, err := server.GetStateCostV2Chunked(serverClient, *sub, chunkOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s cost of resources: %w", name, err)
+		}
+		states[name] = state
+	}
+	return states, nil
+}
 
-	return nil
+// priceRecommendations prices the resources with the values suggested by each recommendation rule
+func priceRecommendations(serverClient server.ServerClient, resources []schema.ResourceDef, rules []recommendation.Rule, chunkOptions server.ChunkOptions) (map[string]*cost.ModularState, []recommendation.Candidate, error) {
+	states := make(map[string]*cost.ModularState)
+	var candidates []recommendation.Candidate
+	for _, rule := range rules {
+		ruleResources, ruleCandidates := recommendation.Resources(resources, rule)
+		if len(ruleCandidates) == 0 {
+			continue
+		}
+		sub, err := schema.CreateSubmission(ruleResources)
+		if err != nil {
+			return nil, nil, err
+		}
+		state, err := server.GetStateCostChunked(serverClient, *sub, chunkOptions)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get cost of resources with %s: %w", rule.Name, err)
+		}
+		states[rule.Name] = &cost.ModularState{Resources: state.Resources}
+		candidates = append(candidates, ruleCandidates...)
+	}
+	return states, candidates, nil
+}
+
+// priceRecommendationsV2 prices the resources of the module with the values suggested by each recommendation rule
+func priceRecommendationsV2(serverClient server.ServerClient, module schema.ModuleDef, rules []recommendation.Rule, chunkOptions server.ChunkOptions) (map[string]*cost.ModularState, []recommendation.Candidate, error) {
+	states := make(map[string]*cost.ModularState)
+	var candidates []recommendation.Candidate
+	for _, rule := range rules {
+		ruleModule, ruleCandidates := recommendation.Module(module, rule)
+		if len(ruleCandidates) == 0 {
+			continue
+		}
+		sub, err := schema.CreateSubmissionV2(ruleModule)
+		if err != nil {
+			return nil, nil, err
+		}
+		state, err := server.GetStateCostV2Chunked(serverClient, *sub, chunkOptions)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get cost of resources with %s: %w", rule.Name, err)
+		}
+		states[rule.Name] = state
+		candidates = append(candidates, ruleCandidates...)
+	}
+	return states, candidates, nil
 }
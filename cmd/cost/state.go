@@ -0,0 +1,46 @@
+package cost
+
+import (
+	"github.com/kaytu-io/pennywise/cmd/cost/terraform"
+	"github.com/kaytu-io/pennywise/cmd/flags"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	usagePackage "github.com/kaytu-io/pennywise/pkg/usage"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var stateCommand = &cobra.Command{
+	Use:   "state",
+	Short: `Shows the costs of the deployed resources in a terraform state file.`,
+	Long:  `Shows the costs of the deployed resources by reading a terraform state file or the output of terraform show -json.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usage, err := flags.ReadUsage(cmd)
+		if err != nil {
+			return err
+		}
+		opts, err := readOutputOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+		chunkOptions, err := readChunkOptions(cmd)
+		if err != nil {
+			return err
+		}
+		region := terraform.StateDefaultRegion(flags.ReadStringFlag(cmd, "region"))
+		return estimateTerraformState(opts, flags.ReadStringFlag(cmd, "state-path"), region, usage, pkg.DefaultServerAddress, chunkOptions)
+	},
+}
+
+func estimateTerraformState(opts outputOptions, statePath string, region string, usage usagePackage.Usage, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
+	file, err := os.Open(statePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	resources, err := terraform.ParseTerraformState(file, usage, region)
+	if err != nil {
+		return err
+	}
+	return estimateResources(opts, resources, ServerClientAddress, chunkOptions)
+}
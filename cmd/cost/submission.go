@@ -1,10 +1,8 @@
 package cost
 
 import (
-	"fmt"
 	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/pkg"
-	outputCost "github.com/kaytu-io/pennywise/pkg/output/cost"
 	"github.com/kaytu-io/pennywise/pkg/schema"
 	"github.com/kaytu-io/pennywise/pkg/server"
 	"github.com/spf13/cobra"
@@ -15,10 +13,16 @@ var submissionCommand = &cobra.Command{
 	Short: `Shows a submission cost.`,
 	Long:  `Shows a submission cost.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		classic := flags.ReadBooleanFlag(cmd, "classic")
-
 		submissionId := flags.ReadStringFlag(cmd, "submission-id")
-		err := estimateSubmission(classic, submissionId, pkg.DefaultServerAddress)
+		opts, err := readOutputOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+		chunkOptions, err := readChunkOptions(cmd)
+		if err != nil {
+			return err
+		}
+		err = estimateSubmission(opts, submissionId, pkg.DefaultServerAddress, chunkOptions)
 		if err != nil {
 			return err
 		}
@@ -27,7 +31,7 @@ var submissionCommand = &cobra.Command{
 	},
 }
 
-func estimateSubmission(classic bool, submissionId string, ServerClientAddress string) error {
+func estimateSubmission(opts outputOptions, submissionId string, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
 	serverClient, err := server.NewPennywiseServerClient(ServerClientAddress)
 	if err != nil {
 		return err
@@ -36,23 +40,10 @@ func estimateSubmission(classic bool, submissionId string, ServerClientAddress s
 	if err != nil {
 		return err
 	}
-	state, err := serverClient.GetStateCostV2(*sub)
+	state, err := server.GetStateCostV2Chunked(serverClient, *sub, chunkOptions)
 	if err != nil {
 		return err
 	}
-	if classic {
-		costString, err := state.ToClassicState().CostString()
-		if err != nil {
-			return err
-		}
-		fmt.Println(costString)
-		fmt.Println("To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md")
-	} else {
-		err = outputCost.ShowStateCosts(state)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	state.SetAttributes(sub.ResourceAttributes())
+	return showCost(opts, state)
 }
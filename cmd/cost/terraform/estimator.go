@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"fmt"
 	"github.com/kaytu-io/pennywise/pkg/parser/aws"
 	"github.com/kaytu-io/pennywise/pkg/parser/azurerm"
 	terraform2 "github.com/kaytu-io/pennywise/pkg/parser/terraform"
@@ -8,6 +9,7 @@ import (
 	"github.com/kaytu-io/pennywise/pkg/usage"
 	"io"
 	"os"
+	"strings"
 )
 
 var (
@@ -41,6 +43,10 @@ func ParseTerraformPlanJson(plan io.Reader, u usage.Usage) ([]schema.ResourceDef
 	if err != nil {
 		return nil, err
 	}
+	// The diagnostics are shown on stderr so they don't mix with the results
+	for _, diagnostic := range tfplan.Diagnostics() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", diagnostic)
+	}
 	var resources []schema.ResourceDef
 	for _, rs := range plannedQueries {
 		res := rs.ToResource(defaultRegion)
@@ -48,3 +54,62 @@ func ParseTerraformPlanJson(plan io.Reader, u usage.Usage) ([]schema.ResourceDef
 	}
 	return resources, nil
 }
+
+// ParseTerraformState is a helper function that reads a Terraform state file or the json output of its
+// `terraform show -json` using the provided io.Reader and returns the deployed resources.
+// The state has no provider configuration so the region of each resource is read from its attributes,
+// the default region is used for the resources without one and a warning is shown for the ones left without a region.
+func ParseTerraformState(state io.Reader, u usage.Usage, defaultRegion string) ([]schema.ResourceDef, error) {
+	tfstate, err := terraform2.ReadState(state)
+	if err != nil {
+		return nil, err
+	}
+	var resources []schema.ResourceDef
+	var unknownRegion []string
+	for _, rs := range tfstate.ExtractQueries(u) {
+		region := stateRegion(rs.Values)
+		if region == "" {
+			region = defaultRegion
+		}
+		if region == "" {
+			unknownRegion = append(unknownRegion, rs.Address)
+		}
+		resources = append(resources, rs.ToResource(region))
+	}
+	if len(unknownRegion) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: the region of %s could not be found in the state, set it with --region or AWS_REGION\n",
+			strings.Join(unknownRegion, ", "))
+	}
+	return resources, nil
+}
+
+// StateDefaultRegion returns the region used for the state resources without one, the given region
+// or the AWS_REGION and AWS_DEFAULT_REGION environment variables if it's empty
+func StateDefaultRegion(region string) string {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region != "" {
+			break
+		}
+		region = os.Getenv(env)
+	}
+	return region
+}
+
+// stateRegion returns the region of a deployed resource from its region, location, arn or availability zone attribute
+func stateRegion(values map[string]interface{}) string {
+	for _, key := range []string{"region", "location"} {
+		if region, ok := values[key].(string); ok && region != "" {
+			return region
+		}
+	}
+	if arn, ok := values["arn"].(string); ok {
+		// arn:partition:service:region:account-id:resource
+		if parts := strings.Split(arn, ":"); len(parts) > 3 && parts[3] != "" {
+			return parts[3]
+		}
+	}
+	if zone, ok := values["availability_zone"].(string); ok && len(zone) > 1 {
+		return zone[:len(zone)-1]
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package terraform
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// zipMagic is the beginning of the binary plans, they're zip files
+var zipMagic = []byte("PK\x03\x04")
+
+// OpenPlan opens the plan at the path, a json plan is read as is and a binary plan is converted to json by running
+// `show -json` of the terraform binary in the directory of the plan since it needs the initialized configuration
+func OpenPlan(path string, binary string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	header, err := reader.Peek(len(zipMagic))
+	if err != nil && err != io.EOF {
+		file.Close()
+		return nil, err
+	}
+	if !bytes.Equal(header, zipMagic) {
+		return struct {
+			io.Reader
+			io.Closer
+		}{reader, file}, nil
+	}
+	file.Close()
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	binary, err = TerraformBinary(binary)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(binary, "show", "-json", absPath)
+	cmd.Dir = filepath.Dir(absPath)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to read binary plan %s with %s show -json: %w: %s", path, binary, err, stderr.String())
+	}
+	return io.NopCloser(&stdout), nil
+}
+
+// TerraformBinary returns the binary to read the binary plans with, the given one, the one set by
+// pkg.TerraformBinaryEnv or terraform or tofu if it's found in the PATH
+func TerraformBinary(binary string) (string, error) {
+	if binary != "" {
+		return binary, nil
+	}
+	if binary := os.Getenv(pkg.TerraformBinaryEnv); binary != "" {
+		return binary, nil
+	}
+	for _, name := range []string{"terraform", "tofu"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("terraform or tofu is required to read binary plans, set its path with --terraform-binary or %s", pkg.TerraformBinaryEnv)
+}
@@ -1,6 +1,18 @@
 package diff
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/cmd/flags"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/output"
+	outputDiff "github.com/kaytu-io/pennywise/pkg/output/diff"
+	"github.com/kaytu-io/pennywise/pkg/publish"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
 
 // DiffCmd diff commands
 var DiffCmd = &cobra.Command{
@@ -14,12 +26,21 @@ var DiffCmd = &cobra.Command{
 
 func init() {
 	DiffCmd.AddCommand(projectCommand)
-	projectCommand.Flags().String("json-path", "", "terraform plan json file path")
+	projectCommand.Flags().String("plan-path", "", "terraform plan file path, the json of terraform show -json or the binary plan, which needs terraform or tofu (see --terraform-binary)")
+	projectCommand.Flags().String("json-path", "", "alias of --plan-path, accepts binary plans too")
+	projectCommand.Flags().String("terraform-binary", "", "terraform or tofu binary to read binary plans with, PENNYWISE_TERRAFORM_BINARY or the one in the PATH by default")
 	projectCommand.Flags().String("project-path", ".", "path to terraform project")
 	projectCommand.Flags().StringSlice("terraform-var-file", []string{}, "path to terraform variables file")
 	projectCommand.Flags().String("usage", "", "usage file path")
 	projectCommand.Flags().Bool("classic", false, "Show results in classic view (not interactive)")
 	projectCommand.Flags().String("compare-to", "", "submission id to compare other submission with (latest submission by default)")
+	projectCommand.Flags().String("output", output.Interactive, "output format (markdown | json | html), interactive view by default, the html report path can be given as an argument")
+	projectCommand.Flags().Int("chunk-size", server.DefaultChunkSize, "maximum number of resources diffed in a single request")
+	projectCommand.Flags().Int("concurrency", server.DefaultConcurrency, "maximum number of diff requests sent at the same time")
+	projectCommand.Flags().String("publish", "", "post the diff as a pull request comment (github | gitlab | bitbucket), the diff is only shown if --output is set")
+	projectCommand.Flags().String("currency", "", "currency to convert the costs to (e.g. EUR), using the rates of the exchange rates file")
+	projectCommand.Flags().String("exchange-rates", "", "path to the exchange rates file (json | yaml), PENNYWISE_EXCHANGE_RATES by default")
+	projectCommand.Flags().Bool("fail-on-missing-prices", false, "fail if the price of any component could not be found")
 
 	DiffCmd.AddCommand(submissionCommand)
 	submissionCommand.Flags().String("submission-id", "", "submission id")
@@ -27,4 +48,170 @@ func init() {
 	submissionCommand.Flags().String("compare-to", "", "submission id to compare other submission with")
 	submissionCommand.MarkFlagRequired("compare-to")
 	submissionCommand.Flags().Bool("classic", false, "Show results in classic view (not interactive)")
+	submissionCommand.Flags().String("output", output.Interactive, "output format (markdown | json | html), interactive view by default, the html report path can be given as an argument")
+	submissionCommand.Flags().Int("chunk-size", server.DefaultChunkSize, "maximum number of resources diffed in a single request")
+	submissionCommand.Flags().Int("concurrency", server.DefaultConcurrency, "maximum number of diff requests sent at the same time")
+	submissionCommand.Flags().String("publish", "", "post the diff as a pull request comment (github | gitlab | bitbucket), the diff is only shown if --output is set")
+	submissionCommand.Flags().String("currency", "", "currency to convert the costs to (e.g. EUR), using the rates of the exchange rates file")
+	submissionCommand.Flags().String("exchange-rates", "", "path to the exchange rates file (json | yaml), PENNYWISE_EXCHANGE_RATES by default")
+	submissionCommand.Flags().Bool("fail-on-missing-prices", false, "fail if the price of any component could not be found")
+}
+
+// outputOptions defines how the diff is shown
+type outputOptions struct {
+	format    string
+	classic   bool
+	publisher string
+	// file is the path of the html report
+	file     string
+	currency string
+	// rates are the exchange rates to convert the costs to the currency, nil if no currency is set
+	rates *cost.ExchangeRates
+	// failOnMissingPrices fails the command if any component could not be priced
+	failOnMissingPrices bool
+}
+
+// readOutputOptions reads the flags defining the output format, the currency and the publisher,
+// the first argument is the path of the html report
+func readOutputOptions(cmd *cobra.Command, args []string) (outputOptions, error) {
+	var file string
+	if len(args) > 0 {
+		file = args[0]
+	}
+	currency := strings.ToUpper(flags.ReadStringFlag(cmd, "currency"))
+	rates, err := cost.LoadCurrencyRates(currency, flags.ReadStringFlag(cmd, "exchange-rates"))
+	if err != nil {
+		return outputOptions{}, err
+	}
+	return outputOptions{
+		currency:  currency,
+		rates:     rates,
+		format:    flags.ReadStringFlag(cmd, "output"),
+		classic:   flags.ReadBooleanFlag(cmd, "classic"),
+		publisher: flags.ReadStringFlag(cmd, "publish"),
+		file:      file,
+
+		failOnMissingPrices: flags.ReadBooleanFlag(cmd, "fail-on-missing-prices"),
+	}, nil
+}
+
+// readChunkOptions reads the flags defining how big submissions are split into diff requests
+func readChunkOptions(cmd *cobra.Command) (server.ChunkOptions, error) {
+	chunkSize, err := cmd.Flags().GetInt("chunk-size")
+	if err != nil {
+		return server.ChunkOptions{}, err
+	}
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return server.ChunkOptions{}, err
+	}
+	return server.ChunkOptions{
+		ChunkSize:   chunkSize,
+		Concurrency: concurrency,
+		Progress:    printProgress,
+	}, nil
+}
+
+// printProgress shows the number of diffed chunks on stderr so it doesn't mix with the results
+func printProgress(done, total int) {
+	fmt.Fprintf(os.Stderr, "\rdiffing resources: %d/%d chunks", done, total)
+	if done == total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// showDiff publishes the diff if a publisher is defined, shows it in the requested output format
+// and checks the missing prices
+func showDiff(opts outputOptions, stateDiff *schema.ModularStateDiff) error {
+	if opts.rates != nil {
+		err := stateDiff.ConvertCurrency(opts.rates, opts.currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.publisher != "" {
+		err := publishDiff(opts.publisher, stateDiff)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.publisher == "" || opts.format != output.Interactive || opts.classic {
+		err := printDiff(opts, stateDiff)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.failOnMissingPrices {
+		return checkMissingPrices(stateDiff)
+	}
+	return nil
+}
+
+// printDiff shows the diff in the requested output format,
+// the classic view is used instead of the interactive view if the terminal is not interactive
+func printDiff(opts outputOptions, stateDiff *schema.ModularStateDiff) error {
+	switch opts.format {
+	case output.Interactive:
+		if opts.classic || !output.IsInteractive() {
+			costString, err := outputDiff.CostString(stateDiff)
+			if err != nil {
+				return err
+			}
+			fmt.Println(costString)
+			fmt.Println("To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md")
+			return nil
+		}
+		return outputDiff.ShowStateCosts(stateDiff)
+	case output.Markdown:
+		fmt.Println(outputDiff.MarkdownString(stateDiff, outputDiff.MaxCommentLength))
+		return nil
+	case output.JSON:
+		jsonString, err := outputDiff.JSONString(stateDiff)
+		if err != nil {
+			return err
+		}
+		fmt.Println(jsonString)
+		return nil
+	case output.HTML:
+		page, err := outputDiff.HTMLString(stateDiff)
+		if err != nil {
+			return err
+		}
+		path, err := output.WriteHTMLReport(opts.file, page)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("report is written to %s\n", path)
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %s", opts.format)
+	}
+}
+
+// publishDiff creates or updates the pull request comment of the diff
+func publishDiff(name string, stateDiff *schema.ModularStateDiff) error {
+	publisher, err := publish.NewPublisher(name)
+	if err != nil {
+		return err
+	}
+	maxLength := publisher.MaxLength() - len(publish.CommentBody(""))
+	body := publish.CommentBody(outputDiff.MarkdownString(stateDiff, maxLength))
+	err = publisher.Publish(body)
+	if err != nil {
+		return fmt.Errorf("failed to publish diff to %s: %w", publisher.Name(), err)
+	}
+	fmt.Printf("diff is published to %s\n", publisher.Name())
+	return nil
+}
+
+// checkMissingPrices fails if the price of any component could not be found,
+// the components are listed on stderr so they don't mix with the results
+func checkMissingPrices(stateDiff *schema.ModularStateDiff) error {
+	unpriced := stateDiff.UnpricedComponents()
+	if len(unpriced) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, cost.UnpricedString(unpriced))
+	return cost.UnpricedError(len(unpriced))
 }
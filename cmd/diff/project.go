@@ -1,21 +1,16 @@
 package diff
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/kaytu-io/infracost/external/providers"
 	"github.com/kaytu-io/pennywise/cmd/cost/terraform"
 	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/pkg"
-	outputDiff "github.com/kaytu-io/pennywise/pkg/output/diff"
 	"github.com/kaytu-io/pennywise/pkg/parser/hcl"
 	"github.com/kaytu-io/pennywise/pkg/schema"
 	"github.com/kaytu-io/pennywise/pkg/server"
 	usagePackage "github.com/kaytu-io/pennywise/pkg/usage"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
-	"os"
-	"path/filepath"
 )
 
 var projectCommand = &cobra.Command{
@@ -23,45 +18,32 @@ var projectCommand = &cobra.Command{
 	Short: `Shows the costs by parsing a project resources.`,
 	Long:  `Shows the costs by parsing a project resources.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		usagePath := flags.ReadStringOptionalFlag(cmd, "usage")
-		var usage usagePackage.Usage
-		if usagePath != nil {
-			usageFile, err := os.Open(*usagePath)
-			if err != nil {
-				return fmt.Errorf("error while reading usage file %s", err)
-			}
-			defer usageFile.Close()
-
-			ext := filepath.Ext(*usagePath)
-			switch ext {
-			case ".json":
-				err = json.NewDecoder(usageFile).Decode(&usage)
-			case ".yaml", ".yml":
-				err = yaml.NewDecoder(usageFile).Decode(&usage)
-			default:
-				return fmt.Errorf("unsupported file format %s for usage file", ext)
-			}
-			if err != nil {
-				return fmt.Errorf("error while parsing usage file %s", err)
-			}
-
-		} else {
-			usage = usagePackage.Usage{}
+		usage, err := flags.ReadUsage(cmd)
+		if err != nil {
+			return err
 		}
 
-		classic := flags.ReadBooleanFlag(cmd, "classic")
+		opts, err := readOutputOptions(cmd, args)
+		if err != nil {
+			return err
+		}
 		compareTo := flags.ReadStringFlag(cmd, "compare-to")
 
-		jsonPath := flags.ReadStringOptionalFlag(cmd, "json-path")
+		chunkOptions, err := readChunkOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		planPath := flags.ReadPlanPathFlag(cmd)
 		projectPath := flags.ReadStringFlag(cmd, "project-path")
 		tfVarFiles := flags.ReadStringArrayFlag(cmd, "terraform-var-file")
-		if jsonPath != nil {
-			err := tfPlanJsonDiff(classic, *jsonPath, compareTo, usage, pkg.DefaultServerAddress)
+		if planPath != nil {
+			err := tfPlanJsonDiff(opts, *planPath, flags.ReadStringFlag(cmd, "terraform-binary"), compareTo, usage, pkg.DefaultServerAddress, chunkOptions)
 			if err != nil {
 				return err
 			}
 		} else {
-			err := terraformProjectDiff(classic, projectPath, compareTo, usage, pkg.DefaultServerAddress, tfVarFiles)
+			err := terraformProjectDiff(opts, projectPath, compareTo, usage, pkg.DefaultServerAddress, tfVarFiles, chunkOptions)
 			if err != nil {
 				return err
 			}
@@ -70,14 +52,12 @@ var projectCommand = &cobra.Command{
 	},
 }
 
-func tfPlanJsonDiff(classic bool, jsonPath string, compareToId string, usage usagePackage.Usage, ServerClientAddress string) error {
-	if classic {
-		return fmt.Errorf("classic view not available for diff")
-	}
-	file, err := os.Open(jsonPath)
+func tfPlanJsonDiff(opts outputOptions, planPath string, terraformBinary string, compareToId string, usage usagePackage.Usage, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
+	file, err := terraform.OpenPlan(planPath, terraformBinary)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 	resources, err := terraform.ParseTerraformPlanJson(file, usage)
 	if err != nil {
 		return err
@@ -113,7 +93,7 @@ func tfPlanJsonDiff(classic bool, jsonPath string, compareToId string, usage usa
 		Current:   *sub,
 		CompareTo: *compareTo,
 	}
-	stateDiff, err := serverClient.GetSubmissionsDiff(req)
+	stateDiff, err := server.GetSubmissionsDiffChunked(serverClient, req, chunkOptions)
 	if err != nil {
 		return err
 	}
@@ -122,17 +102,16 @@ func tfPlanJsonDiff(classic bool, jsonPath string, compareToId string, usage usa
 		PriorCost: stateDiff.PriorCost,
 		NewCost:   stateDiff.NewCost,
 	}
-	err = outputDiff.ShowStateCosts(&modularShowDiff)
+	modularShowDiff.SetPlannedChanges(sub.PlannedChanges())
+	modularShowDiff.SetReservedUpfront(sub.ResourceAttributes(), compareTo.ResourceAttributes())
+	err = showDiff(opts, &modularShowDiff)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func terraformProjectDiff(classic bool, projectPath string, compareToId string, usage usagePackage.Usage, ServerClientAddress string, tfVarFiles []string) error {
-	if classic {
-		return fmt.Errorf("classic view not available for diff")
-	}
+func terraformProjectDiff(opts outputOptions, projectPath string, compareToId string, usage usagePackage.Usage, ServerClientAddress string, tfVarFiles []string, chunkOptions server.ChunkOptions) error {
 	var project *schema.ModuleDef
 	var err error
 	if providers.IsTerragruntNestedDir(projectPath, 5) {
@@ -176,11 +155,12 @@ func terraformProjectDiff(classic bool, projectPath string, compareToId string,
 		CompareTo: *compareTo,
 	}
 
-	stateDiff, err := serverClient.GetSubmissionsDiffV2(req)
+	stateDiff, err := server.GetSubmissionsDiffV2Chunked(serverClient, req, chunkOptions)
 	if err != nil {
 		return err
 	}
-	err = outputDiff.ShowStateCosts(stateDiff)
+	stateDiff.SetReservedUpfront(sub.ResourceAttributes(), compareTo.ResourceAttributes())
+	err = showDiff(opts, stateDiff)
 	if err != nil {
 		return err
 	}
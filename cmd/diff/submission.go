@@ -1,10 +1,8 @@
 package diff
 
 import (
-	"fmt"
 	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/pkg"
-	outputDiff "github.com/kaytu-io/pennywise/pkg/output/diff"
 	"github.com/kaytu-io/pennywise/pkg/schema"
 	"github.com/kaytu-io/pennywise/pkg/server"
 	"github.com/spf13/cobra"
@@ -15,12 +13,18 @@ var submissionCommand = &cobra.Command{
 	Short: `Shows a submission cost.`,
 	Long:  `Shows a submission cost.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		classic := flags.ReadBooleanFlag(cmd, "classic")
-
 		submissionId := flags.ReadStringFlag(cmd, "submission-id")
 		compareTo := flags.ReadStringFlag(cmd, "compare-to")
 
-		err := submissionsDiff(classic, submissionId, compareTo, pkg.DefaultServerAddress)
+		opts, err := readOutputOptions(cmd, args)
+		if err != nil {
+			return err
+		}
+		chunkOptions, err := readChunkOptions(cmd)
+		if err != nil {
+			return err
+		}
+		err = submissionsDiff(opts, submissionId, compareTo, pkg.DefaultServerAddress, chunkOptions)
 		if err != nil {
 			return err
 		}
@@ -29,7 +33,7 @@ var submissionCommand = &cobra.Command{
 	},
 }
 
-func submissionsDiff(classic bool, submissionId, compareToId string, ServerClientAddress string) error {
+func submissionsDiff(opts outputOptions, submissionId, compareToId string, ServerClientAddress string, chunkOptions server.ChunkOptions) error {
 	serverClient, err := server.NewPennywiseServerClient(ServerClientAddress)
 	if err != nil {
 		return err
@@ -47,17 +51,14 @@ func submissionsDiff(classic bool, submissionId, compareToId string, ServerClien
 		Current:   *sub,
 		CompareTo: *compareTo,
 	}
-	stateDiff, err := serverClient.GetSubmissionsDiffV2(req)
+	stateDiff, err := server.GetSubmissionsDiffV2Chunked(serverClient, req, chunkOptions)
 	if err != nil {
 		return err
 	}
-	if classic {
-		return fmt.Errorf("classic view not available for diff")
-	} else {
-		err = outputDiff.ShowStateCosts(stateDiff)
-		if err != nil {
-			return err
-		}
+	stateDiff.SetReservedUpfront(sub.ResourceAttributes(), compareTo.ResourceAttributes())
+	err = showDiff(opts, stateDiff)
+	if err != nil {
+		return err
 	}
 	return nil
 }
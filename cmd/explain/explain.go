@@ -0,0 +1,123 @@
+package explain
+
+import (
+	"fmt"
+	"github.com/kaytu-io/infracost/external/providers"
+	"github.com/kaytu-io/pennywise/cmd/cost/terraform"
+	"github.com/kaytu-io/pennywise/cmd/flags"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/parser/hcl"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	usagePackage "github.com/kaytu-io/pennywise/pkg/usage"
+	"github.com/spf13/cobra"
+)
+
+// ExplainCmd explain command
+var ExplainCmd = &cobra.Command{
+	Use:   "explain <resource-address>",
+	Short: `Explains how the cost of a resource is calculated.`,
+	Long: `Shows the rate, unit and quantity of each component of the resource, how they're multiplied into the monthly cost
+and the usage keys that influenced the quantities with their source (usage file or plan value).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usage, err := flags.ReadUsage(cmd)
+		if err != nil {
+			return err
+		}
+
+		address := args[0]
+		planPath := flags.ReadPlanPathFlag(cmd)
+		projectPath := flags.ReadStringFlag(cmd, "project-path")
+		tfVarFiles := flags.ReadStringArrayFlag(cmd, "terraform-var-file")
+		var resources []schema.ResourceDef
+		if planPath != nil {
+			resources, err = parseTfPlanJson(*planPath, flags.ReadStringFlag(cmd, "terraform-binary"), usage)
+		} else {
+			resources, err = parseTerraformProject(projectPath, usage, tfVarFiles)
+		}
+		if err != nil {
+			return err
+		}
+		return explainResource(resources, address, pkg.DefaultServerAddress)
+	},
+}
+
+func init() {
+	ExplainCmd.Flags().String("plan-path", "", "terraform plan file path, the json of terraform show -json or the binary plan, which needs terraform or tofu (see --terraform-binary)")
+	ExplainCmd.Flags().String("json-path", "", "alias of --plan-path, accepts binary plans too")
+	ExplainCmd.Flags().String("terraform-binary", "", "terraform or tofu binary to read binary plans with, PENNYWISE_TERRAFORM_BINARY or the one in the PATH by default")
+	ExplainCmd.Flags().String("project-path", ".", "path to terraform project")
+	ExplainCmd.Flags().StringSlice("terraform-var-file", []string{}, "path to terraform variables file")
+	ExplainCmd.Flags().String("usage", "", "usage file path")
+}
+
+func parseTfPlanJson(planPath string, terraformBinary string, usage usagePackage.Usage) ([]schema.ResourceDef, error) {
+	file, err := terraform.OpenPlan(planPath, terraformBinary)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return terraform.ParseTerraformPlanJson(file, usage)
+}
+
+func parseTerraformProject(projectPath string, usage usagePackage.Usage, tfVarFiles []string) ([]schema.ResourceDef, error) {
+	var project *schema.ModuleDef
+	var err error
+	if providers.IsTerragruntNestedDir(projectPath, 5) {
+		project, err = hcl.ParseTerragruntProject(projectPath, usage)
+	} else {
+		project, err = hcl.ParseHclResources(projectPath, usage, tfVarFiles)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sub, err := schema.CreateSubmissionV2(*project)
+	if err != nil {
+		return nil, err
+	}
+	return sub.GetResources(), nil
+}
+
+// explainResource prices only the resource at the address and shows how its cost is calculated,
+// the submission isn't stored so it doesn't change the latest submission the diffs compare to
+func explainResource(resources []schema.ResourceDef, address string, ServerClientAddress string) error {
+	var resource *schema.ResourceDef
+	for _, res := range resources {
+		if res.Address == address {
+			res := res
+			resource = &res
+			break
+		}
+	}
+	if resource == nil {
+		return fmt.Errorf("resource %s not found", address)
+	}
+	serverClient, err := server.NewPennywiseServerClient(ServerClientAddress)
+	if err != nil {
+		return err
+	}
+	sub, err := schema.CreateSubmission([]schema.ResourceDef{*resource})
+	if err != nil {
+		return err
+	}
+	state, err := serverClient.GetStateCost(*sub)
+	if err != nil {
+		return err
+	}
+	modularState := cost.ModularState{
+		Resources: state.Resources,
+	}
+	modularState.SetAttributes(sub.ResourceAttributes())
+	res, ok := modularState.ResourcesByAddress()[address]
+	if !ok {
+		return fmt.Errorf("resource %s is not priced", address)
+	}
+	explanation, err := res.Explain(address)
+	if err != nil {
+		return err
+	}
+	fmt.Println(explanation)
+	return nil
+}
@@ -43,6 +43,14 @@ func ReadStringOptionalFlag(cmd *cobra.Command, name string) *string {
 	return nil
 }
 
+// ReadPlanPathFlag returns the plan file path given by --plan-path or by its alias --json-path, nil if none is given
+func ReadPlanPathFlag(cmd *cobra.Command) *string {
+	if planPath := ReadStringOptionalFlag(cmd, "plan-path"); planPath != nil {
+		return planPath
+	}
+	return ReadStringOptionalFlag(cmd, "json-path")
+}
+
 func ReadInt64Flag(cmd *cobra.Command, name string) int64 {
 	str := ReadStringFlag(cmd, name)
 	i, _ := strconv.ParseInt(str, 10, 64)
@@ -0,0 +1,40 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kaytu-io/pennywise/pkg/usage"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// ReadUsage reads the usage file given by the usage flag, json or yaml, an empty usage if it's not given
+func ReadUsage(cmd *cobra.Command) (usage.Usage, error) {
+	usagePath := ReadStringOptionalFlag(cmd, "usage")
+	if usagePath == nil {
+		return usage.Usage{}, nil
+	}
+	usageFile, err := os.Open(*usagePath)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading usage file %s", err)
+	}
+	defer usageFile.Close()
+
+	var u usage.Usage
+	ext := filepath.Ext(*usagePath)
+	switch ext {
+	case ".json":
+		err = json.NewDecoder(usageFile).Decode(&u)
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(usageFile).Decode(&u)
+	default:
+		return nil, fmt.Errorf("unsupported file format %s for usage file", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing usage file %s", err)
+	}
+	return u, nil
+}
@@ -1,44 +1,116 @@
 package predef
 
 import (
+	"errors"
 	"fmt"
+	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/pkg/api/auth0"
 	"github.com/kaytu-io/pennywise/pkg/server"
 	"github.com/spf13/cobra"
+	"os/exec"
+	"runtime"
 	"time"
 )
 
-const RetrySleep = 3
-const DefaultWorkspace = "kaytu"
+// DefaultPollInterval is the interval used to poll for the access token if the server doesn't define one
+const DefaultPollInterval = 5 * time.Second
+
+// SlowDownInterval is added to the poll interval each time the server asks to slow down
+const SlowDownInterval = 5 * time.Second
 
 var LoginCmd = &cobra.Command{
-	Use: "login",
+	Use:   "login",
+	Short: `Logs in to your kaytu account.`,
+	Long:  `Logs in to your kaytu account by confirming a code in your browser.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deviceCode, err := auth0.RequestDeviceCode()
 		if err != nil {
 			return fmt.Errorf("[login-deviceCode]: %v", err)
 		}
 
-		var accessToken string
-		for i := 0; i < 100; i++ {
-			accessToken, err = auth0.AccessToken(deviceCode)
-			if err != nil {
-				time.Sleep(RetrySleep * time.Second)
-				continue
-			}
-			break
+		fmt.Println("open this url in your browser:")
+		fmt.Println(deviceCode.VerificationUrlComplete)
+		fmt.Println("and confirm this code is shown:", bold.Sprint(deviceCode.UserCode))
+		if !flags.ReadBooleanFlag(cmd, "no-browser") {
+			// the url is printed anyway, so failing to open the browser is ignored
+			_ = openBrowser(deviceCode.VerificationUrlComplete)
 		}
+
+		accessToken, err := pollAccessToken(deviceCode)
 		if err != nil {
 			return fmt.Errorf("[login-accessToken]: %v", err)
 		}
 
+		// the server address of the profile is kept unless it's given again
+		serverURL := server.ProfileServerURL()
+		if cmd.Flags().Changed("server-url") {
+			serverURL = flags.ReadStringFlag(cmd, "server-url")
+		}
 		err = server.SetConfig(server.Config{
-			AccessToken:      accessToken,
-			DefaultWorkspace: DefaultWorkspace,
+			AccessToken:      accessToken.AccessToken,
+			RefreshToken:     accessToken.RefreshToken,
+			DefaultWorkspace: server.ProfileWorkspace(),
+			ServerURL:        serverURL,
 		})
 		if err != nil {
 			return fmt.Errorf("[login-setConfig]: %v", err)
 		}
+
+		about, err := auth0.RequestAbout(accessToken.AccessToken)
+		if err != nil {
+			return fmt.Errorf("[login-about]: %v", err)
+		}
+		fmt.Printf("logged in as %s (profile %s)\n", about.Email, server.ActiveProfile())
 		return nil
 	},
 }
+
+func init() {
+	LoginCmd.Flags().String("server-url", "", "server address used by the profile, kept from the previous login if not given (the default server if empty)")
+	LoginCmd.Flags().Bool("no-browser", false, "don't open the verification url in the browser")
+}
+
+// pollAccessToken polls the access token of the device code in the interval defined by the server
+// until the user confirms the code or the device code expires
+func pollAccessToken(deviceCode *auth0.DeviceCodeResponse) (*auth0.ResponseAccessToken, error) {
+	interval := DefaultPollInterval
+	if deviceCode.Interval > 0 {
+		interval = time.Duration(deviceCode.Interval) * time.Second
+	}
+	var deadline time.Time
+	if deviceCode.ExpiresIn > 0 {
+		deadline = time.Now().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+	}
+
+	for {
+		time.Sleep(interval)
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, auth0.ErrExpiredToken
+		}
+
+		accessToken, err := auth0.AccessToken(deviceCode.DeviceCode)
+		switch {
+		case err == nil:
+			return accessToken, nil
+		case errors.Is(err, auth0.ErrAuthorizationPending):
+			continue
+		case errors.Is(err, auth0.ErrSlowDown):
+			interval += SlowDownInterval
+			continue
+		default:
+			return nil, err
+		}
+	}
+}
+
+// openBrowser opens the url in the default browser of the user
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,5 @@
+package predef
+
+import "github.com/fatih/color"
+
+var bold = color.New(color.Bold)
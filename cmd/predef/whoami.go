@@ -0,0 +1,53 @@
+package predef
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/api/auth0"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var WhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: `Shows the logged in identity.`,
+	Long:  `Shows the logged in identity, the expiry of the session, the active workspace and the server address.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := server.GetConfig()
+		if err != nil {
+			return err
+		}
+
+		identity := "unknown"
+		if about, err := auth0.RequestAbout(config.AccessToken); err == nil && about.Email != "" {
+			identity = about.Email
+		} else if claims, err := server.TokenClaims(config.AccessToken); err == nil {
+			// machine tokens (client credentials) don't have user info
+			if sub, ok := claims["sub"].(string); ok {
+				identity = sub
+			}
+		}
+
+		expiry := "unknown"
+		if exp, err := server.TokenExpirationTime(config.AccessToken); err == nil && !exp.IsZero() {
+			expiry = fmt.Sprintf("%s (in %s)", exp.Format(time.RFC1123), time.Until(exp).Round(time.Minute))
+		}
+
+		workspace := config.DefaultWorkspace
+		if workspace == "" {
+			workspace = "-"
+		}
+		serverURL := config.ServerURL
+		if serverURL == "" {
+			serverURL = pkg.DefaultServerAddress
+		}
+
+		fmt.Printf("%s %s\n", bold.Sprint("Identity:"), identity)
+		fmt.Printf("%s %s\n", bold.Sprint("Profile:"), server.ActiveProfile())
+		fmt.Printf("%s %s\n", bold.Sprint("Session expires:"), expiry)
+		fmt.Printf("%s %s\n", bold.Sprint("Workspace:"), workspace)
+		fmt.Printf("%s %s\n", bold.Sprint("Server:"), serverURL)
+		return nil
+	},
+}
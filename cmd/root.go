@@ -4,7 +4,13 @@ import (
 	"errors"
 	"github.com/kaytu-io/pennywise/cmd/cost"
 	"github.com/kaytu-io/pennywise/cmd/diff"
+	"github.com/kaytu-io/pennywise/cmd/explain"
+	"github.com/kaytu-io/pennywise/cmd/flags"
 	"github.com/kaytu-io/pennywise/cmd/predef"
+	"github.com/kaytu-io/pennywise/cmd/workspace"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/output"
+	"github.com/kaytu-io/pennywise/pkg/server"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -18,16 +24,27 @@ var rootCmd = &cobra.Command{
 		}
 		return cmd.Help()
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		server.SetProfile(flags.ReadStringFlag(cmd, "profile"))
+		if flags.ReadBooleanFlag(cmd, "no-color") {
+			output.DisableColors()
+		}
+	},
 }
 
 func init() {
 	//rootCmd.AddCommand(ingestion.IngestCmd)
 	rootCmd.AddCommand(cost.CostCmd)
 	rootCmd.AddCommand(diff.DiffCmd)
+	rootCmd.AddCommand(explain.ExplainCmd)
 
 	rootCmd.AddCommand(predef.VersionCmd)
 	rootCmd.AddCommand(predef.LoginCmd)
 	rootCmd.AddCommand(predef.LogoutCmd)
+	rootCmd.AddCommand(predef.WhoamiCmd)
+	rootCmd.AddCommand(workspace.WorkspaceCmd)
+	rootCmd.PersistentFlags().Bool("no-color", false, "disable colors in the output (also disabled by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().String("profile", defaultProfile(), "name of the profile to use the credentials and settings of")
 	//rootCmd.PersistentFlags().String("server-url", "https://pennywise.kaytu.dev/kaytu", "define the server http address")
 }
 
@@ -37,3 +54,11 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// defaultProfile returns the profile selected by the environment variable or the default profile
+func defaultProfile() string {
+	if profile := os.Getenv(pkg.ProfileEnv); profile != "" {
+		return profile
+	}
+	return server.DefaultProfile
+}
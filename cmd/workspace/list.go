@@ -0,0 +1,37 @@
+package workspace
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var list = &cobra.Command{
+	Use:   "list",
+	Short: `Returns list of workspaces`,
+	Long:  `Returns list of workspaces of the account, the active one is marked with *`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := server.GetConfig()
+		if err != nil {
+			return err
+		}
+		serverClient, err := server.NewPennywiseServerClient(pkg.DefaultServerAddress)
+		if err != nil {
+			return err
+		}
+		workspaces, err := serverClient.ListWorkspaces()
+		if err != nil {
+			return err
+		}
+
+		for _, ws := range workspaces {
+			if ws.Name == config.DefaultWorkspace {
+				fmt.Println("*", ws.Name)
+			} else {
+				fmt.Println(" ", ws.Name)
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,43 @@
+package workspace
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/server"
+	"github.com/spf13/cobra"
+)
+
+var use = &cobra.Command{
+	Use:   "use <workspace>",
+	Short: `Selects the workspace`,
+	Long:  `Selects the workspace used by the active profile for the next commands`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		serverClient, err := server.NewPennywiseServerClient(pkg.DefaultServerAddress)
+		if err != nil {
+			return err
+		}
+		workspaces, err := serverClient.ListWorkspaces()
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, ws := range workspaces {
+			if ws.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("workspace %s not found", name)
+		}
+
+		err = server.SetWorkspace(name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("workspace %s is selected for profile %s\n", name, server.ActiveProfile())
+		return nil
+	},
+}
@@ -0,0 +1,20 @@
+package workspace
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// WorkspaceCmd workspace commands
+var WorkspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: `Lists and selects the workspace.`,
+	Long:  `Lists the workspaces of the account and selects the one submissions and pricing settings are scoped to.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+func init() {
+	WorkspaceCmd.AddCommand(list)
+	WorkspaceCmd.AddCommand(use)
+}
@@ -10,12 +10,24 @@ import (
 	"net/http"
 )
 
+// Errors returned by the token endpoint while polling for the device code access token
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("polling too fast")
+	ErrExpiredToken         = errors.New("device code has expired")
+	ErrAccessDenied         = errors.New("access denied")
+)
+
 type ResponseAccessToken struct {
-	AccessToken string `json:"access_token"`
-	Scope       string `json:"scope"`
-	IdToken     string `json:"id_token"`
-	TokenType   string `json:"token_type"`
-	ExpireIn    string `json:"expire_in"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	IdToken      string `json:"id_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
 }
 type RequestAccessToken struct {
 	GrantType  string `json:"grant_type"`
@@ -23,47 +35,65 @@ type RequestAccessToken struct {
 	ClientId   string `json:"client_id"`
 }
 
-func AccessToken(deviceCode string) (string, error) {
+func AccessToken(deviceCode string) (*ResponseAccessToken, error) {
 	payload := RequestAccessToken{
 		GrantType:  "urn:ietf:params:oauth:grant-type:device_code",
 		DeviceCode: deviceCode,
 		ClientId:   pkg.Auth0ClientID,
 	}
+	return requestToken(payload)
+}
 
+// requestToken requests a token from the auth0 token endpoint with the given grant payload
+func requestToken(payload interface{}) (*ResponseAccessToken, error) {
 	payloadEncoded, err := json.Marshal(payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequest("POST", fmt.Sprintf("https://%s/oauth/token", pkg.Auth0Hostname), bytes.NewBuffer(payloadEncoded))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Add("content-type", "application/json")
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	err = res.Body.Close()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	response := ResponseAccessToken{}
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	switch response.Error {
+	case "":
+	case "authorization_pending":
+		return nil, ErrAuthorizationPending
+	case "slow_down":
+		return nil, ErrSlowDown
+	case "expired_token":
+		return nil, ErrExpiredToken
+	case "access_denied":
+		return nil, ErrAccessDenied
+	default:
+		return nil, fmt.Errorf("%s: %s", response.Error, response.ErrorDescription)
 	}
 
 	if response.AccessToken == "" {
-		return "", errors.New("access token is empty")
+		return nil, errors.New("access token is empty")
 	}
-	return response.AccessToken, nil
+	return &response, nil
 }
@@ -0,0 +1,28 @@
+package auth0
+
+import (
+	"fmt"
+)
+
+type RequestClientCredentials struct {
+	GrantType    string `json:"grant_type"`
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Audience     string `json:"audience"`
+}
+
+// ClientCredentialsToken requests an access token using the OAuth client credentials grant,
+// used to authenticate machines like CI runners without a browser.
+func ClientCredentialsToken(clientId, clientSecret string) (*ResponseAccessToken, error) {
+	payload := RequestClientCredentials{
+		GrantType:    "client_credentials",
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		Audience:     Audience,
+	}
+	response, err := requestToken(payload)
+	if err != nil {
+		return nil, fmt.Errorf("[clientCredentialsToken] : %v", err)
+	}
+	return response, nil
+}
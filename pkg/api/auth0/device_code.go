@@ -9,6 +9,9 @@ import (
 	"net/http"
 )
 
+// Audience is the API audience the access tokens are requested for
+const Audience = "https://app.kaytu.io"
+
 type DeviceCodeRequest struct {
 	ClientId string `json:"client_id"`
 	Scope    string `json:"scope"`
@@ -24,49 +27,48 @@ type DeviceCodeResponse struct {
 	Interval                int    `json:"interval"`
 }
 
-func RequestDeviceCode() (string, error) {
+// RequestDeviceCode starts the device authorization flow, the user should open the verification url
+// and confirm the user code while the device code is polled for an access token.
+func RequestDeviceCode() (*DeviceCodeResponse, error) {
 	payload := DeviceCodeRequest{
 		ClientId: pkg.Auth0ClientID,
-		Scope:    "openid profil email api:read",
-		Audience: "https://app.kaytu.io",
+		Scope:    "openid profil email api:read offline_access",
+		Audience: Audience,
 	}
 	payloadEncode, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 	}
 
 	req, err := http.NewRequest("POST", fmt.Sprintf("https://%s/oauth/device/code", pkg.Auth0Hostname), bytes.NewBuffer(payloadEncode))
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 	}
 	req.Header.Add("content-type", "application/json")
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 	}
 	err = res.Body.Close()
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("invalid status code: %d, %s", res.StatusCode, string(body))
+		return nil, fmt.Errorf("invalid status code: %d, %s", res.StatusCode, string(body))
 	}
 
 	response := DeviceCodeResponse{}
 	err = json.Unmarshal(body, &response)
 	if err != nil {
-		return "", fmt.Errorf("[requestDeviceCode] : %v", err)
+		return nil, fmt.Errorf("[requestDeviceCode] : %v", err)
 	}
 
-	fmt.Println("open this url in your browser:")
-	fmt.Println(response.VerificationUrlComplete)
-
-	return response.DeviceCode, nil
+	return &response, nil
 }
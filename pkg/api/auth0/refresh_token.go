@@ -0,0 +1,26 @@
+package auth0
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+)
+
+type RequestRefreshToken struct {
+	GrantType    string `json:"grant_type"`
+	ClientId     string `json:"client_id"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshAccessToken requests a new access token using the refresh token received on login.
+func RefreshAccessToken(refreshToken string) (*ResponseAccessToken, error) {
+	payload := RequestRefreshToken{
+		GrantType:    "refresh_token",
+		ClientId:     pkg.Auth0ClientID,
+		RefreshToken: refreshToken,
+	}
+	response, err := requestToken(payload)
+	if err != nil {
+		return nil, fmt.Errorf("[refreshAccessToken] : %v", err)
+	}
+	return response, nil
+}
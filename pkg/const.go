@@ -5,3 +5,21 @@ const KaytuHostname = "api.kaytu.io"
 const Auth0ClientID = "4a9U7TriDk3j5TDueRDR1JKwINKECzUG"
 const PennywiseDir = ".pennywise"
 const DefaultServerAddress string = "https://pennywise.kaytu.dev/kaytu"
+
+// Environment variables used to authenticate without `pennywise login`, e.g. on CI runners
+const (
+	ProfileEnv      = "PENNYWISE_PROFILE"
+	WorkspaceEnv    = "PENNYWISE_WORKSPACE"
+	APIKeyEnv       = "PENNYWISE_API_KEY"
+	ClientIDEnv     = "PENNYWISE_CLIENT_ID"
+	ClientSecretEnv = "PENNYWISE_CLIENT_SECRET"
+)
+
+// ExchangeRatesEnv is the path of the exchange rates file used if --exchange-rates is not set
+const ExchangeRatesEnv = "PENNYWISE_EXCHANGE_RATES"
+
+// RecommendationRulesEnv is the path of the recommendation rules file used if --recommendation-rules is not set
+const RecommendationRulesEnv = "PENNYWISE_RECOMMENDATION_RULES"
+
+// TerraformBinaryEnv is the terraform or tofu binary used to read binary plans if --terraform-binary is not set
+const TerraformBinaryEnv = "PENNYWISE_TERRAFORM_BINARY"
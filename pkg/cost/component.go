@@ -1,6 +1,7 @@
 package cost
 
 import (
+	"encoding/json"
 	"github.com/shopspring/decimal"
 )
 
@@ -14,10 +15,29 @@ type Component struct {
 	Rate            Cost
 	Details         []string
 	Usage           bool
+	// UpfrontQuantity is the quantity paid once at the Rate, e.g. the upfront payment of a reserved instance
+	UpfrontQuantity decimal.Decimal
+	// UpfrontMonths is the number of months the upfront payment is amortized over, DefaultUpfrontMonths if it's not set
+	UpfrontMonths int
+	// UpfrontEstimated is set if the upfront payment is estimated from the payment option instead of being priced
+	UpfrontEstimated bool
+	// PurchaseOption is the purchase option the component is priced with (PurchaseOptionOnDemand, PurchaseOptionReserved
+	// or PurchaseOptionSpot), empty if the pricing server doesn't set it
+	PurchaseOption string
 
 	Error error
 }
 
+// Purchase options of the components
+const (
+	PurchaseOptionOnDemand = "on_demand"
+	PurchaseOptionReserved = "reserved"
+	PurchaseOptionSpot     = "spot"
+)
+
+// DefaultUpfrontMonths is the number of months the upfront payments are amortized over if the term is unknown
+const DefaultUpfrontMonths = 12
+
 // GetRounded returns component with rounded values to show
 func (c Component) GetRounded() Component {
 	return Component{
@@ -28,13 +48,29 @@ func (c Component) GetRounded() Component {
 		Rate:            Cost{Decimal: c.Rate.Decimal.Round(3), Currency: c.Rate.Currency},
 		Details:         c.Details,
 		Usage:           c.Usage,
+		UpfrontQuantity: c.UpfrontQuantity.Round(3),
+		UpfrontMonths:   c.UpfrontMonths,
+
+		UpfrontEstimated: c.UpfrontEstimated,
+		PurchaseOption:   c.PurchaseOption,
 
 		Error: c.Error,
 	}
 }
 
-// Cost returns the cost of this component (Rate multiplied by Quantity).
+// Cost returns the monthly cost of this component (Rate multiplied by Quantity)
+// including the upfront payment amortized over its months.
 func (c Component) Cost() Cost {
+	recurring := c.RecurringCost()
+	amortized := c.AmortizedUpfrontCost()
+	if amortized.IsZero() {
+		return recurring
+	}
+	return Cost{Decimal: recurring.Decimal.Add(amortized.Decimal), Currency: c.Rate.Currency}
+}
+
+// RecurringCost returns the monthly cost of this component without the upfront payment.
+func (c Component) RecurringCost() Cost {
 	if !c.MonthlyQuantity.IsZero() {
 		return c.Rate.MulDecimal(c.MonthlyQuantity)
 	} else if !c.HourlyQuantity.IsZero() {
@@ -43,3 +79,99 @@ func (c Component) Cost() Cost {
 		return Zero
 	}
 }
+
+// UpfrontCost returns the one-time cost of this component (Rate multiplied by UpfrontQuantity).
+func (c Component) UpfrontCost() Cost {
+	if c.UpfrontQuantity.IsZero() {
+		return Zero
+	}
+	return c.Rate.MulDecimal(c.UpfrontQuantity)
+}
+
+// AmortizedUpfrontCost returns the monthly share of the upfront cost over the months it's amortized over.
+func (c Component) AmortizedUpfrontCost() Cost {
+	upfront := c.UpfrontCost()
+	if upfront.IsZero() {
+		return Zero
+	}
+	return Cost{Decimal: upfront.Decimal.Div(decimal.NewFromInt(int64(c.Months()))), Currency: upfront.Currency}
+}
+
+// Months returns the number of months the upfront payment is amortized over
+func (c Component) Months() int {
+	if c.UpfrontMonths <= 0 {
+		return DefaultUpfrontMonths
+	}
+	return c.UpfrontMonths
+}
+
+// IsPriced checks if the price of the component was found
+func (c Component) IsPriced() bool {
+	return c.Error == nil
+}
+
+// componentError is an error of a component decoded from json
+type componentError string
+
+func (e componentError) Error() string {
+	return string(e)
+}
+
+// UnmarshalJSON decodes the component, the error can be a message or an object with a message
+// since errors don't have a json representation
+func (c *Component) UnmarshalJSON(data []byte) error {
+	type component Component
+	var raw struct {
+		component
+		Error json.RawMessage
+	}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+	*c = Component(raw.component)
+	c.Error = decodeComponentError(raw.Error)
+	return nil
+}
+
+// MarshalJSON encodes the component with the error message
+func (c Component) MarshalJSON() ([]byte, error) {
+	type component Component
+	var errorMessage *string
+	if c.Error != nil {
+		msg := c.Error.Error()
+		errorMessage = &msg
+	}
+	return json.Marshal(struct {
+		component
+		Error *string
+	}{component(c), errorMessage})
+}
+
+func decodeComponentError(data json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	var msg string
+	if json.Unmarshal(data, &msg) != nil {
+		var obj map[string]interface{}
+		if json.Unmarshal(data, &obj) != nil {
+			return componentError(string(data))
+		}
+		for _, key := range []string{"message", "Message", "error", "Error", "msg"} {
+			if m, ok := obj[key].(string); ok {
+				msg = m
+				break
+			}
+		}
+	}
+	switch msg {
+	case ErrPriceNotFound.Error():
+		return ErrPriceNotFound
+	case ErrProductNotFound.Error():
+		return ErrProductNotFound
+	case "":
+		return ErrPriceNotFound
+	}
+	return componentError(msg)
+}
@@ -0,0 +1,198 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the prices if the server doesn't return one
+const DefaultCurrency = "USD"
+
+// ExchangeRates are the rates of the currencies against the base currency,
+// e.g. with USD as the base, a rate of 0.92 for EUR means 1 USD is 0.92 EUR
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]decimal.Decimal
+}
+
+// exchangeRatesFile is the content of the rates file
+type exchangeRatesFile struct {
+	Base  string             `json:"base" yaml:"base"`
+	Rates map[string]float64 `json:"rates" yaml:"rates"`
+}
+
+// LoadExchangeRates reads the exchange rates from a json or yaml file
+func LoadExchangeRates(path string) (*ExchangeRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading exchange rates file %s", err)
+	}
+
+	var file exchangeRatesFile
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("unsupported file format %s for exchange rates file", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing exchange rates file %s", err)
+	}
+
+	rates := &ExchangeRates{
+		Base:  strings.ToUpper(file.Base),
+		Rates: make(map[string]decimal.Decimal),
+	}
+	if rates.Base == "" {
+		rates.Base = DefaultCurrency
+	}
+	for currency, rate := range file.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("exchange rate of %s must be positive", currency)
+		}
+		rates.Rates[strings.ToUpper(currency)] = decimal.NewFromFloat(rate)
+	}
+	return rates, nil
+}
+
+// LoadCurrencyRates returns the exchange rates to convert the costs to the currency, read from the file at the path
+// or the one set by the PENNYWISE_EXCHANGE_RATES environment variable. Returns nil if no currency is set.
+func LoadCurrencyRates(currency, path string) (*ExchangeRates, error) {
+	if currency == "" {
+		return nil, nil
+	}
+	if path == "" {
+		path = os.Getenv(pkg.ExchangeRatesEnv)
+	}
+	if path == "" {
+		if strings.ToUpper(currency) == DefaultCurrency {
+			return &ExchangeRates{Base: DefaultCurrency}, nil
+		}
+		return nil, fmt.Errorf("exchange rates file is required to convert the costs to %s, set it with --exchange-rates or %s", currency, pkg.ExchangeRatesEnv)
+	}
+	rates, err := LoadExchangeRates(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rates.Rate(DefaultCurrency, strings.ToUpper(currency)); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// Rate returns the rate to convert an amount from a currency to another
+func (r *ExchangeRates) Rate(from, to string) (decimal.Decimal, error) {
+	if from == "" {
+		from = DefaultCurrency
+	}
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	fromRate, err := r.baseRate(from)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	toRate, err := r.baseRate(to)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return toRate.Div(fromRate), nil
+}
+
+func (r *ExchangeRates) baseRate(currency string) (decimal.Decimal, error) {
+	if currency == r.Base {
+		return decimal.NewFromInt(1), nil
+	}
+	rate, ok := r.Rates[currency]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("exchange rate of %s is not found", currency)
+	}
+	return rate, nil
+}
+
+// Convert converts the cost to the currency, costs without a currency are in DefaultCurrency
+func (r *ExchangeRates) Convert(c Cost, to string) (Cost, error) {
+	if c == Zero {
+		return c, nil
+	}
+	rate, err := r.Rate(c.Currency, to)
+	if err != nil {
+		return Zero, err
+	}
+	return Cost{Decimal: c.Decimal.Mul(rate), Currency: to}, nil
+}
+
+// ConvertComponent returns the component with its rate converted to the currency, so all of its costs are in it
+func (r *ExchangeRates) ConvertComponent(c Component, to string) (Component, error) {
+	rate, err := r.Convert(c.Rate, to)
+	if err != nil {
+		return c, err
+	}
+	c.Rate = rate
+	return c, nil
+}
+
+// ConvertCurrency converts the rates of every component in the module and its child modules to the currency,
+// so the costs of the components, resources and modules are all in the same currency
+func (s *ModularState) ConvertCurrency(r *ExchangeRates, to string) error {
+	for name, res := range s.Resources {
+		components := make(map[string][]Component, len(res.Components))
+		for label, comps := range res.Components {
+			for _, c := range comps {
+				converted, err := r.ConvertComponent(c, to)
+				if err != nil {
+					return fmt.Errorf("failed to convert cost of resource %s: %w", name, err)
+				}
+				components[label] = append(components[label], converted)
+			}
+		}
+		if res.Components != nil {
+			res.Components = components
+		}
+		s.Resources[name] = res
+	}
+	for name, child := range s.ChildModules {
+		err := child.ConvertCurrency(r, to)
+		if err != nil {
+			return err
+		}
+		s.ChildModules[name] = child
+	}
+	return nil
+}
+
+// MoneyFormatter returns the formatter of the amounts in the currency using its symbol and separators.
+// The precision is the number of decimal places for currencies with cents and it's reduced for the others.
+func MoneyFormatter(currency string, precision int) *accounting.Accounting {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	locale, ok := accounting.LocaleInfo[currency]
+	if !ok {
+		return &accounting.Accounting{Symbol: currency + " ", Precision: precision}
+	}
+	if locale.FractionLength < 2 {
+		precision -= 2 - locale.FractionLength
+		if precision < 0 {
+			precision = 0
+		}
+	}
+	decimalSeparator := locale.DecSep
+	if decimalSeparator == "" {
+		decimalSeparator = "."
+	}
+	format := "%s%v"
+	if !locale.Pre {
+		format = "%v %s"
+	}
+	return accounting.NewAccounting(locale.ComSymbol, precision, locale.ThouSep, decimalSeparator, format, "-"+format, format)
+}
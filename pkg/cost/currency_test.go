@@ -0,0 +1,213 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/shopspring/decimal"
+)
+
+// writeRatesFile writes the exchange rates file with the name in a temporary directory and returns its path
+func writeRatesFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadExchangeRates(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantBase string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "json",
+			file:     "rates.json",
+			content:  `{"base": "usd", "rates": {"eur": 0.92, "GBP": 0.79}}`,
+			wantBase: "USD",
+			want:     map[string]string{"EUR": "0.92", "GBP": "0.79"},
+		},
+		{
+			name:     "yaml",
+			file:     "rates.yaml",
+			content:  "base: EUR\nrates:\n  USD: 1.09\n  JPY: 162\n",
+			wantBase: "EUR",
+			want:     map[string]string{"USD": "1.09", "JPY": "162"},
+		},
+		{
+			name:     "yml without a base",
+			file:     "rates.yml",
+			content:  "rates:\n  EUR: 0.92\n",
+			wantBase: DefaultCurrency,
+			want:     map[string]string{"EUR": "0.92"},
+		},
+		{name: "unsupported format", file: "rates.txt", content: "EUR 0.92", wantErr: true},
+		{name: "invalid json", file: "rates.json", content: `{"rates": [0.92]}`, wantErr: true},
+		{name: "rate not positive", file: "rates.json", content: `{"rates": {"EUR": 0}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := LoadExchangeRates(writeRatesFile(t, tt.file, tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadExchangeRates() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadExchangeRates() error = %v", err)
+			}
+			if rates.Base != tt.wantBase {
+				t.Errorf("Base = %s, want %s", rates.Base, tt.wantBase)
+			}
+			if len(rates.Rates) != len(tt.want) {
+				t.Errorf("Rates = %v, want %v", rates.Rates, tt.want)
+			}
+			for currency, want := range tt.want {
+				if got := rates.Rates[currency]; !got.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("Rates[%s] = %s, want %s", currency, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCurrencyRates(t *testing.T) {
+	flagFile := writeRatesFile(t, "flag.json", `{"rates": {"EUR": 0.9}}`)
+	envFile := writeRatesFile(t, "env.yaml", "rates:\n  EUR: 0.8\n  GBP: 0.7\n")
+	tests := []struct {
+		name     string
+		currency string
+		path     string
+		env      string
+		wantNil  bool
+		// wantRate is the rate of rateOf against the default currency
+		rateOf   string
+		wantRate string
+		wantErr  bool
+	}{
+		{name: "no currency", wantNil: true},
+		{name: "default currency without rates", currency: "usd", rateOf: "USD", wantRate: "1"},
+		{name: "file from the flag", currency: "eur", path: flagFile, env: envFile, rateOf: "EUR", wantRate: "0.9"},
+		{name: "file from the env", currency: "GBP", env: envFile, rateOf: "GBP", wantRate: "0.7"},
+		{name: "no file", currency: "EUR", wantErr: true},
+		{name: "unknown currency", currency: "CHF", path: flagFile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(pkg.ExchangeRatesEnv, tt.env)
+			rates, err := LoadCurrencyRates(tt.currency, tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadCurrencyRates() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCurrencyRates() error = %v", err)
+			}
+			if tt.wantNil {
+				if rates != nil {
+					t.Errorf("LoadCurrencyRates() = %v, want nil", rates)
+				}
+				return
+			}
+			rate, err := rates.Rate(DefaultCurrency, tt.rateOf)
+			if err != nil {
+				t.Fatalf("Rate() error = %v", err)
+			}
+			if !rate.Equal(decimal.RequireFromString(tt.wantRate)) {
+				t.Errorf("Rate() = %s, want %s", rate, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestExchangeRatesConvert(t *testing.T) {
+	rates := &ExchangeRates{Base: "USD", Rates: map[string]decimal.Decimal{
+		"EUR": decimal.RequireFromString("0.8"),
+		"GBP": decimal.RequireFromString("0.5"),
+	}}
+	tests := []struct {
+		name    string
+		cost    Cost
+		to      string
+		want    Cost
+		wantErr bool
+	}{
+		{name: "from the base", cost: Cost{Decimal: decimal.NewFromInt(10), Currency: "USD"}, to: "EUR", want: Cost{Decimal: decimal.NewFromInt(8), Currency: "EUR"}},
+		{name: "without a currency", cost: Cost{Decimal: decimal.NewFromInt(10)}, to: "EUR", want: Cost{Decimal: decimal.NewFromInt(8), Currency: "EUR"}},
+		{name: "between two rates", cost: Cost{Decimal: decimal.NewFromInt(8), Currency: "EUR"}, to: "GBP", want: Cost{Decimal: decimal.NewFromInt(5), Currency: "GBP"}},
+		{name: "to the same currency", cost: Cost{Decimal: decimal.NewFromInt(8), Currency: "EUR"}, to: "EUR", want: Cost{Decimal: decimal.NewFromInt(8), Currency: "EUR"}},
+		{name: "zero", cost: Zero, to: "EUR", want: Zero},
+		{name: "unknown target currency", cost: Cost{Decimal: decimal.NewFromInt(10), Currency: "USD"}, to: "CHF", wantErr: true},
+		{name: "unknown source currency", cost: Cost{Decimal: decimal.NewFromInt(10), Currency: "CHF"}, to: "EUR", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(tt.cost, tt.to)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Convert() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !got.Decimal.Equal(tt.want.Decimal) || got.Currency != tt.want.Currency {
+				t.Errorf("Convert() = %s %s, want %s %s", got.Decimal, got.Currency, tt.want.Decimal, tt.want.Currency)
+			}
+		})
+	}
+}
+
+func TestModularStateConvertCurrency(t *testing.T) {
+	rates := &ExchangeRates{Base: "USD", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.8")}}
+	newState := func() ModularState {
+		return ModularState{
+			Resources: map[string]Resource{"aws_instance.web": {Components: map[string][]Component{"compute": {{
+				HourlyQuantity: decimal.NewFromInt(1), Rate: Cost{Decimal: decimal.NewFromFloat(0.1), Currency: "USD"},
+			}}}}},
+			ChildModules: map[string]ModularState{"module.db": {
+				Resources: map[string]Resource{"aws_db_instance.db": {Components: map[string][]Component{"storage": {{
+					MonthlyQuantity: decimal.NewFromInt(100), Rate: Cost{Decimal: decimal.NewFromFloat(0.1)},
+				}}}}},
+			}},
+		}
+	}
+
+	state := newState()
+	if err := state.ConvertCurrency(rates, "EUR"); err != nil {
+		t.Fatalf("ConvertCurrency() error = %v", err)
+	}
+	got, err := state.Cost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := decimal.RequireFromString("66.4") // (73 + 10) × 0.8
+	if !got.Decimal.Equal(want) || got.Currency != "EUR" {
+		t.Errorf("Cost() = %s %s, want %s EUR", got.Decimal, got.Currency, want)
+	}
+
+	// converting the converted state again keeps the costs
+	if err := state.ConvertCurrency(rates, "EUR"); err != nil {
+		t.Fatalf("second ConvertCurrency() error = %v", err)
+	}
+	if again, err := state.Cost(); err != nil || !again.Decimal.Equal(want) || again.Currency != "EUR" {
+		t.Errorf("Cost() after converting twice = %s %s (%v), want %s EUR", again.Decimal, again.Currency, err, want)
+	}
+
+	unknown := newState()
+	if err := unknown.ConvertCurrency(rates, "CHF"); err == nil {
+		t.Errorf("ConvertCurrency() to an unknown currency error = nil, want an error")
+	}
+}
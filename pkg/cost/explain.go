@@ -0,0 +1,102 @@
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explain returns the lines showing how the monthly cost of the component is calculated from its rate and quantities
+func (c Component) Explain() []string {
+	ac := MoneyFormatter(c.Rate.Currency, 2)
+	rate := fmt.Sprintf("%s %s per %s", c.Rate.Decimal.String(), currencyCode(c.Rate.Currency), c.Unit)
+	lines := []string{fmt.Sprintf("Rate: %s", rate)}
+	if !c.IsPriced() {
+		return append(lines, fmt.Sprintf("%s %v, it's not included in the costs", UnpricedMark, c.Error))
+	}
+
+	recurring := c.RecurringCost()
+	switch {
+	case !c.MonthlyQuantity.IsZero():
+		lines = append(lines,
+			fmt.Sprintf("Monthly quantity: %s %s", c.MonthlyQuantity.String(), c.Unit),
+			fmt.Sprintf("Monthly cost: %s × %s = %s", c.Rate.Decimal.String(), c.MonthlyQuantity.String(), ac.FormatMoney(recurring.Decimal)))
+	case !c.HourlyQuantity.IsZero():
+		lines = append(lines,
+			fmt.Sprintf("Hourly quantity: %s %s", c.HourlyQuantity.String(), c.Unit),
+			fmt.Sprintf("Monthly cost: %s × %s × %s hours per month = %s", c.Rate.Decimal.String(), c.HourlyQuantity.String(),
+				HoursPerMonth.String(), ac.FormatMoney(recurring.Decimal)))
+	default:
+		lines = append(lines, "No quantity, the monthly cost is 0")
+	}
+
+	if upfront := c.UpfrontCost(); !upfront.IsZero() {
+		amortized := c.AmortizedUpfrontCost()
+		lines = append(lines,
+			fmt.Sprintf("Upfront cost (one-time): %s × %s = %s", c.Rate.Decimal.String(), c.UpfrontQuantity.String(), ac.FormatMoney(upfront.Decimal)),
+			fmt.Sprintf("Amortized upfront cost: %s / %d months = %s per month", ac.FormatMoney(upfront.Decimal), c.Months(), ac.FormatMoney(amortized.Decimal)),
+			fmt.Sprintf("Total monthly cost: %s + %s = %s", ac.FormatMoney(recurring.Decimal), ac.FormatMoney(amortized.Decimal), ac.FormatMoney(c.Cost().Decimal)))
+		if c.UpfrontEstimated {
+			lines = append(lines, "The upfront cost is estimated from the payment option in the usage, the priced upfront fee may differ")
+		}
+	}
+	for _, detail := range c.Details {
+		lines = append(lines, fmt.Sprintf("Detail: %s", detail))
+	}
+	return lines
+}
+
+// Explain returns a string showing how the monthly cost of each component of the resource is calculated
+// and the usage keys that influenced the quantities with their sources
+func (re Resource) Explain(address string) (string, error) {
+	total, err := re.Cost()
+	if err != nil {
+		return "", err
+	}
+	ac := MoneyFormatter(total.Currency, 2)
+
+	var sb strings.Builder
+	sb.WriteString(bold.Sprintf("%s (%s)", address, re.Type))
+	if re.Region != "" {
+		sb.WriteString(fmt.Sprintf(" in %s", re.Region))
+	}
+	sb.WriteString("\n")
+	if !re.IsSupported {
+		sb.WriteString("Resource type is not supported, it's not included in the costs\n")
+		return sb.String(), nil
+	}
+
+	labels := make([]string, 0, len(re.Components))
+	for label := range re.Components {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		for _, c := range re.Components[label] {
+			sb.WriteString("\n" + bold.Sprint(c.Name) + "\n")
+			for _, line := range c.Explain() {
+				sb.WriteString("  " + line + "\n")
+			}
+		}
+	}
+	if len(re.Components) == 0 {
+		sb.WriteString("\nResource has no cost components, it's free\n")
+	}
+
+	if len(re.Usage) > 0 {
+		sb.WriteString("\n" + bold.Sprint("Usage") + "\n")
+		for _, u := range re.Usage {
+			sb.WriteString(fmt.Sprintf("  %s = %v (%s)\n", u.Key, u.Value, u.Source))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n%s %s", bold.Sprint("Total Monthly Cost:"), ac.FormatMoney(total.Decimal)))
+	return sb.String(), nil
+}
+
+// currencyCode returns the currency code, DefaultCurrency if it's empty
+func currencyCode(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,202 @@
+package cost
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"sort"
+	"strings"
+)
+
+// Resource attributes the costs can be grouped by
+const (
+	GroupByType     = "type"
+	GroupByRegion   = "region"
+	GroupByProvider = "provider"
+	GroupByService  = "service"
+	GroupByTag      = "tag"
+)
+
+// UntaggedGroup is the group of the resources without the tag when grouping by a tag
+const UntaggedGroup = "untagged"
+
+// unknownGroup is the group of the resources without the grouped attribute
+const unknownGroup = "unknown"
+
+// Grouping defines how the costs of the resources are aggregated
+type Grouping struct {
+	By string
+	// TagKey is the tag grouped by when By is GroupByTag
+	TagKey string
+}
+
+// Name returns the name of the grouping, tag:<key> for the tag groupings
+func (g Grouping) Name() string {
+	if g.By == GroupByTag {
+		return GroupByTag + ":" + g.TagKey
+	}
+	return g.By
+}
+
+// Groupings returns the groupings by type, region, provider and service family,
+// and by each of the tag keys
+func Groupings(tagKeys []string) []Grouping {
+	groupings := []Grouping{
+		{By: GroupByType},
+		{By: GroupByRegion},
+		{By: GroupByProvider},
+		{By: GroupByService},
+	}
+	for _, key := range tagKeys {
+		if key != "" {
+			groupings = append(groupings, Grouping{By: GroupByTag, TagKey: key})
+		}
+	}
+	return groupings
+}
+
+// CostGroup is the total cost of the resources sharing the same value of the grouped attribute
+type CostGroup struct {
+	Name      string
+	Cost      Cost
+	Resources []string
+	// Upfront is the one-time upfront cost of the resources, AmortizedUpfront is its monthly share included in Cost
+	Upfront          Cost
+	AmortizedUpfront Cost
+	// UpfrontPayments are the upfront costs of the resources by the months of their terms
+	UpfrontPayments UpfrontPayments
+}
+
+// GroupCosts aggregates the costs of every resource in the module and its child modules by the grouping.
+// The groups are sorted by their cost.
+func (s *ModularState) GroupCosts(g Grouping) ([]CostGroup, error) {
+	groups := make(map[string]*CostGroup)
+	err := groupModuleCosts(*s, "", g, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CostGroup
+	for _, group := range groups {
+		sort.Strings(group.Resources)
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Cost.Decimal.Equal(result[j].Cost.Decimal) {
+			return result[i].Cost.Decimal.GreaterThan(result[j].Cost.Decimal)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// GroupsCostString returns a string to show the costs for the period aggregated by each of the groupings
+func (s *ModularState) GroupsCostString(groupings []Grouping, p Period) (string, error) {
+	var sections []string
+	for _, g := range groupings {
+		groups, err := s.GroupCosts(g)
+		if err != nil {
+			return "", err
+		}
+
+		t := table.NewWriter()
+		t.Style().Options.DrawBorder = false
+		t.Style().Options.SeparateColumns = false
+		t.Style().Options.SeparateRows = false
+		t.Style().Options.SeparateHeader = false
+		t.Style().Format.Header = text.FormatDefault
+		t.SetColumnConfigs([]table.ColumnConfig{
+			{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+			{Number: 2, Align: text.AlignRight, AlignHeader: text.AlignRight},
+			{Number: 3, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		})
+		t.AppendHeader(table.Row{underline.Sprint(g.Name()), underline.Sprint("Resources"), underline.Sprint(p.Title())})
+		for _, group := range groups {
+			ac := MoneyFormatter(group.Cost.Currency, p.Precision())
+			t.AppendRow(table.Row{group.Name, len(group.Resources), ac.FormatMoney(group.CostForPeriod(p).Decimal)})
+		}
+		sections = append(sections, fmt.Sprintf("%s\n%s", bold.Sprintf("Costs by %s", g.Name()), t.Render()))
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
+
+func groupModuleCosts(state ModularState, path string, g Grouping, groups map[string]*CostGroup) error {
+	for name, res := range state.Resources {
+		address := res.Address
+		if address == "" {
+			address = joinAddress(path, name)
+		}
+		resCost, err := res.Cost()
+		if err != nil {
+			return fmt.Errorf("failed to get cost of resource %s: %w", address, err)
+		}
+
+		groupName := g.groupName(res)
+		group, ok := groups[groupName]
+		if !ok {
+			group = &CostGroup{Name: groupName, UpfrontPayments: make(UpfrontPayments)}
+			groups[groupName] = group
+		}
+		group.Cost, err = group.Cost.Add(resCost)
+		if err != nil {
+			return fmt.Errorf("failed to add cost of resource %s: %w", address, err)
+		}
+		upfront, amortized, err := res.UpfrontCosts()
+		if err != nil {
+			return fmt.Errorf("failed to get upfront cost of resource %s: %w", address, err)
+		}
+		group.Upfront, err = group.Upfront.Add(upfront)
+		if err != nil {
+			return fmt.Errorf("failed to add upfront cost of resource %s: %w", address, err)
+		}
+		group.AmortizedUpfront, err = group.AmortizedUpfront.Add(amortized)
+		if err != nil {
+			return fmt.Errorf("failed to add upfront cost of resource %s: %w", address, err)
+		}
+		payments, err := res.UpfrontPayments()
+		if err != nil {
+			return fmt.Errorf("failed to get upfront cost of resource %s: %w", address, err)
+		}
+		if err := group.UpfrontPayments.merge(payments); err != nil {
+			return fmt.Errorf("failed to add upfront cost of resource %s: %w", address, err)
+		}
+		group.Resources = append(group.Resources, address)
+	}
+	for name, child := range state.ChildModules {
+		err := groupModuleCosts(child, joinAddress(path, name), g, groups)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g Grouping) groupName(res Resource) string {
+	var name string
+	switch g.By {
+	case GroupByType:
+		name = res.Type
+	case GroupByRegion:
+		name = res.Region
+	case GroupByProvider:
+		name = res.Provider
+	case GroupByService:
+		name = ServiceFamily(res.Type)
+	case GroupByTag:
+		name = res.Tags[g.TagKey]
+		if name == "" {
+			return UntaggedGroup
+		}
+	}
+	if name == "" {
+		return unknownGroup
+	}
+	return name
+}
+
+func joinAddress(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return strings.Join([]string{path, name}, ".")
+}
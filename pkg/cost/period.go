@@ -0,0 +1,230 @@
+package cost
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// Names of the periods the costs can be shown for
+const (
+	PeriodHourly  = "hourly"
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodYearly  = "yearly"
+)
+
+// Views of the upfront payments in the costs
+const (
+	// ViewAmortized spreads the upfront payments over the months they cover
+	ViewAmortized = "amortized"
+	// ViewCashFlow shows the upfront payments in full in the periods their terms start in
+	ViewCashFlow = "cash-flow"
+)
+
+// Period is the time horizon of the shown costs. The costs are priced per month and converted
+// to the period by the number of hours in it.
+type Period struct {
+	Name  string
+	Hours decimal.Decimal
+	// Months is set for the projections of the total cost over a number of months
+	Months int
+	// CashFlow is set if the upfront payments are included in full instead of being amortized
+	CashFlow bool
+}
+
+// Monthly is the default period the costs are priced for
+var Monthly = Period{Name: PeriodMonthly, Hours: HoursPerMonth}
+
+// NewPeriod returns the period by its name (hourly, daily, monthly or yearly).
+// If months is positive the period is the projection of the total cost over that many months instead.
+func NewPeriod(name string, months int) (Period, error) {
+	if months < 0 {
+		return Period{}, fmt.Errorf("number of months must be positive, got %d", months)
+	}
+	if months > 0 {
+		return Period{
+			Name:   fmt.Sprintf("%d months", months),
+			Hours:  HoursPerMonth.Mul(decimal.NewFromInt(int64(months))),
+			Months: months,
+		}, nil
+	}
+	switch name {
+	case PeriodHourly:
+		return Period{Name: PeriodHourly, Hours: decimal.NewFromInt(1)}, nil
+	case PeriodDaily:
+		return Period{Name: PeriodDaily, Hours: decimal.NewFromInt(24)}, nil
+	case PeriodMonthly, "":
+		return Monthly, nil
+	case PeriodYearly:
+		return Period{Name: PeriodYearly, Hours: HoursPerMonth.Mul(decimal.NewFromInt(12))}, nil
+	}
+	return Period{}, fmt.Errorf("unsupported period %s, it should be one of hourly, daily, monthly or yearly", name)
+}
+
+// WithView returns the period with the view of the upfront payments (amortized or cash-flow)
+func (p Period) WithView(view string) (Period, error) {
+	switch view {
+	case ViewAmortized, "":
+		p.CashFlow = false
+	case ViewCashFlow:
+		p.CashFlow = true
+	default:
+		return Period{}, fmt.Errorf("unsupported cost view %s, it should be one of amortized or cash-flow", view)
+	}
+	return p, nil
+}
+
+// View returns the view of the upfront payments of the period
+func (p Period) View() string {
+	if p.CashFlow {
+		return ViewCashFlow
+	}
+	return ViewAmortized
+}
+
+// IsMonthly checks if the costs of the period are the same as the monthly amortized costs
+func (p Period) IsMonthly() bool {
+	return (p.Name == Monthly.Name || p.Name == "") && !p.CashFlow
+}
+
+// Title returns the title of the costs of the period, like "Monthly Cost" or "Cost over 6 months",
+// or "Monthly Cash Flow" in the cash flow view
+func (p Period) Title() string {
+	if p.CashFlow {
+		switch p.Name {
+		case PeriodHourly:
+			return "Hourly Cash Flow"
+		case PeriodDaily:
+			return "Daily Cash Flow"
+		case PeriodMonthly, "":
+			return "Monthly Cash Flow"
+		case PeriodYearly:
+			return "Yearly Cash Flow"
+		}
+		return fmt.Sprintf("Cash Flow over %s", p.Name)
+	}
+	switch p.Name {
+	case PeriodHourly:
+		return "Hourly Cost"
+	case PeriodDaily:
+		return "Daily Cost"
+	case PeriodMonthly, "":
+		return "Monthly Cost"
+	case PeriodYearly:
+		return "Yearly Cost"
+	}
+	return fmt.Sprintf("Cost over %s", p.Name)
+}
+
+// Precision returns the number of decimal places the costs of the period are shown with,
+// hourly costs need more precision to not be rounded to zero
+func (p Period) Precision() int {
+	if p.Name == PeriodHourly {
+		return 4
+	}
+	return 2
+}
+
+// Convert converts the monthly cost to the cost of the period
+func (p Period) Convert(c Cost) Cost {
+	return Cost{Decimal: p.ConvertDecimal(c.Decimal), Currency: c.Currency}
+}
+
+// ConvertDecimal converts the monthly amount to the amount of the period
+func (p Period) ConvertDecimal(d decimal.Decimal) decimal.Decimal {
+	if p.Hours.IsZero() || p.Hours.Equal(HoursPerMonth) {
+		return d
+	}
+	return d.Mul(p.Hours).Div(HoursPerMonth)
+}
+
+// TermStarts returns the number of terms of the months starting in the period, the upfront payment of a term is paid
+// at its start. A projection over a number of months starts with a term and counts its renewals in the months, a recurring
+// period counts the terms starting in every period of its length, none if it's shorter than the term.
+func (p Period) TermStarts(months int) int {
+	if months <= 0 {
+		months = DefaultUpfrontMonths
+	}
+	if p.Months > 0 {
+		return (p.Months + months - 1) / months
+	}
+	termHours := HoursPerMonth.Mul(decimal.NewFromInt(int64(months)))
+	return int(p.Hours.Div(termHours).Floor().IntPart())
+}
+
+// ConvertWithUpfront converts the monthly cost, which includes the amortized upfront payments, to the cost of the period.
+// In the cash flow view the amortized share is replaced by the upfront payments of the terms starting in the period.
+func (p Period) ConvertWithUpfront(monthly, amortizedUpfront Cost, payments UpfrontPayments) Cost {
+	if !p.CashFlow {
+		return p.Convert(monthly)
+	}
+	c := Cost{Decimal: p.ConvertDecimal(monthly.Decimal.Sub(amortizedUpfront.Decimal)), Currency: monthly.Currency}
+	for months, upfront := range payments {
+		if c.Currency == "" {
+			c.Currency = upfront.Currency
+		}
+		c.Decimal = c.Decimal.Add(upfront.Decimal.Mul(decimal.NewFromInt(int64(p.TermStarts(months)))))
+	}
+	return c
+}
+
+// CostForPeriod returns the cost of the component for the period
+func (c Component) CostForPeriod(p Period) Cost {
+	return p.ConvertWithUpfront(c.Cost(), c.AmortizedUpfrontCost(), c.UpfrontPayments())
+}
+
+// CostForPeriod returns the sum of the costs of every Component of the Resource for the period
+func (re Resource) CostForPeriod(p Period) (Cost, error) {
+	c, err := re.Cost()
+	if err != nil {
+		return Zero, err
+	}
+	_, amortized, err := re.UpfrontCosts()
+	if err != nil {
+		return Zero, err
+	}
+	payments, err := re.UpfrontPayments()
+	if err != nil {
+		return Zero, err
+	}
+	return p.ConvertWithUpfront(c, amortized, payments), nil
+}
+
+// CostForPeriod returns the total cost of the module and its child modules for the period
+func (s *ModularState) CostForPeriod(p Period) (Cost, error) {
+	c, err := s.Cost()
+	if err != nil {
+		return Zero, err
+	}
+	_, amortized, err := s.UpfrontCosts()
+	if err != nil {
+		return Zero, err
+	}
+	payments, err := s.UpfrontPayments()
+	if err != nil {
+		return Zero, err
+	}
+	return p.ConvertWithUpfront(c, amortized, payments), nil
+}
+
+// CostForPeriod returns the total cost of the resources for the period
+func (s *State) CostForPeriod(p Period) (Cost, error) {
+	c, err := s.Cost()
+	if err != nil {
+		return Zero, err
+	}
+	_, amortized, err := resourcesUpfrontCosts(s.Resources)
+	if err != nil {
+		return Zero, err
+	}
+	payments, err := resourcesUpfrontPayments(s.Resources)
+	if err != nil {
+		return Zero, err
+	}
+	return p.ConvertWithUpfront(c, amortized, payments), nil
+}
+
+// CostForPeriod returns the total cost of the resources in the group for the period
+func (g CostGroup) CostForPeriod(p Period) Cost {
+	return p.ConvertWithUpfront(g.Cost, g.AmortizedUpfront, g.UpfrontPayments)
+}
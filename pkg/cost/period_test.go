@@ -0,0 +1,289 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// mustPeriod returns the period by its name or months with the view, it fails the test on an error
+func mustPeriod(t *testing.T, name string, months int, view string) Period {
+	t.Helper()
+	p, err := NewPeriod(name, months)
+	if err != nil {
+		t.Fatalf("NewPeriod(%q, %d) error = %v", name, months, err)
+	}
+	p, err = p.WithView(view)
+	if err != nil {
+		t.Fatalf("WithView(%q) error = %v", view, err)
+	}
+	return p
+}
+
+func TestComponentCostForPeriodWithUpfront(t *testing.T) {
+	// a reserved instance with a 1-year term: 36.5 per month recurring and 262.8 paid upfront, amortized as 21.9 per month
+	c := Component{
+		Name:            "Compute (reserved, partial upfront)",
+		HourlyQuantity:  decimal.NewFromFloat(0.05),
+		Rate:            Cost{Decimal: decimal.NewFromInt(1), Currency: "USD"},
+		UpfrontQuantity: decimal.NewFromFloat(262.8),
+		UpfrontMonths:   12,
+	}
+	tests := []struct {
+		name   string
+		period string
+		months int
+		view   string
+		want   string
+	}{
+		{name: "amortized hourly", period: PeriodHourly, view: ViewAmortized, want: "0.08"},
+		{name: "amortized monthly", period: PeriodMonthly, view: ViewAmortized, want: "58.4"},
+		{name: "amortized yearly", period: PeriodYearly, view: ViewAmortized, want: "700.8"},
+		{name: "amortized 36 months", months: 36, view: ViewAmortized, want: "2102.4"},
+		{name: "cash flow hourly has no term start", period: PeriodHourly, view: ViewCashFlow, want: "0.05"},
+		{name: "cash flow daily has no term start", period: PeriodDaily, view: ViewCashFlow, want: "1.2"},
+		{name: "cash flow monthly has no term start", period: PeriodMonthly, view: ViewCashFlow, want: "36.5"},
+		{name: "cash flow yearly has a term start", period: PeriodYearly, view: ViewCashFlow, want: "700.8"},
+		{name: "cash flow 6 months starts the term", months: 6, view: ViewCashFlow, want: "481.8"},
+		{name: "cash flow 18 months has a renewal", months: 18, view: ViewCashFlow, want: "1182.6"},
+		{name: "cash flow 36 months has two renewals", months: 36, view: ViewCashFlow, want: "2102.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.CostForPeriod(mustPeriod(t, tt.period, tt.months, tt.view))
+			if want := decimal.RequireFromString(tt.want); !got.Decimal.Equal(want) {
+				t.Errorf("CostForPeriod() = %s, want %s", got.Decimal, want)
+			}
+			if got.Currency != "USD" {
+				t.Errorf("CostForPeriod() currency = %s, want USD", got.Currency)
+			}
+		})
+	}
+}
+
+func TestPeriodTermStarts(t *testing.T) {
+	tests := []struct {
+		name   string
+		period string
+		months int
+		term   int
+		want   int
+	}{
+		{name: "hourly", period: PeriodHourly, term: 12, want: 0},
+		{name: "monthly", period: PeriodMonthly, term: 12, want: 0},
+		{name: "yearly", period: PeriodYearly, term: 12, want: 1},
+		{name: "yearly with a 3-year term", period: PeriodYearly, term: 36, want: 0},
+		{name: "36 months", months: 36, term: 12, want: 3},
+		{name: "37 months", months: 37, term: 12, want: 4},
+		{name: "12 months with a 3-year term", months: 12, term: 36, want: 1},
+		{name: "default term", period: PeriodYearly, term: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mustPeriod(t, tt.period, tt.months, ViewCashFlow)
+			if got := p.TermStarts(tt.term); got != tt.want {
+				t.Errorf("TermStarts(%d) = %d, want %d", tt.term, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModularStateCostForPeriodWithUpfront(t *testing.T) {
+	usd := func(f float64) Cost { return Cost{Decimal: decimal.NewFromFloat(f), Currency: "USD"} }
+	state := ModularState{
+		Resources: map[string]Resource{
+			"aws_instance.one_year": {Components: map[string][]Component{"compute": {{
+				Name: "reserved", HourlyQuantity: decimal.NewFromFloat(0.05), Rate: usd(1),
+				UpfrontQuantity: decimal.NewFromFloat(262.8), UpfrontMonths: 12,
+			}}}},
+		},
+		ChildModules: map[string]ModularState{
+			"module.db": {Resources: map[string]Resource{
+				"aws_db_instance.three_years": {Components: map[string][]Component{"compute": {{
+					Name: "reserved", HourlyQuantity: decimal.NewFromFloat(0.1), Rate: usd(1),
+					UpfrontQuantity: decimal.NewFromFloat(1576.8), UpfrontMonths: 36,
+				}}}},
+			}},
+		},
+	}
+	// the 1-year term is paid three times and the 3-year term once in 36 months
+	got, err := state.CostForPeriod(mustPeriod(t, "", 36, ViewCashFlow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := decimal.RequireFromString("6307.2") // 36.5×36 + 3×262.8 + 73×36 + 1576.8
+	if !got.Decimal.Equal(want) {
+		t.Errorf("CostForPeriod() = %s, want %s", got.Decimal, want)
+	}
+	amortized, err := state.CostForPeriod(mustPeriod(t, "", 36, ViewAmortized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !amortized.Decimal.Equal(got.Decimal) {
+		t.Errorf("amortized cost over the terms = %s, want the cash flow %s", amortized.Decimal, got.Decimal)
+	}
+
+	yearly, err := state.CostForPeriod(mustPeriod(t, PeriodYearly, 0, ViewCashFlow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := decimal.RequireFromString("1576.8"); !yearly.Decimal.Equal(want) { // 438 + 262.8 + 876
+		t.Errorf("yearly CostForPeriod() = %s, want %s", yearly.Decimal, want)
+	}
+}
+
+func TestNewPeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    string
+		months    int
+		wantName  string
+		wantHours string
+		wantErr   bool
+	}{
+		{name: "hourly", period: PeriodHourly, wantName: PeriodHourly, wantHours: "1"},
+		{name: "daily", period: PeriodDaily, wantName: PeriodDaily, wantHours: "24"},
+		{name: "monthly", period: PeriodMonthly, wantName: PeriodMonthly, wantHours: "730"},
+		{name: "default", wantName: PeriodMonthly, wantHours: "730"},
+		{name: "yearly", period: PeriodYearly, wantName: PeriodYearly, wantHours: "8760"},
+		{name: "months", months: 6, wantName: "6 months", wantHours: "4380"},
+		{name: "months override the name", period: PeriodHourly, months: 2, wantName: "2 months", wantHours: "1460"},
+		{name: "zero months use the name", period: PeriodDaily, months: 0, wantName: PeriodDaily, wantHours: "24"},
+		{name: "negative months", months: -1, wantErr: true},
+		{name: "negative months with a name", period: PeriodYearly, months: -12, wantErr: true},
+		{name: "unsupported name", period: "weekly", wantErr: true},
+		{name: "name in capitals", period: "Monthly", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPeriod(tt.period, tt.months)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NewPeriod(%q, %d) error = nil, want an error", tt.period, tt.months)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewPeriod(%q, %d) error = %v", tt.period, tt.months, err)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", got.Name, tt.wantName)
+			}
+			if want := decimal.RequireFromString(tt.wantHours); !got.Hours.Equal(want) {
+				t.Errorf("Hours = %s, want %s", got.Hours, want)
+			}
+			if got.Months != tt.months {
+				t.Errorf("Months = %d, want %d", got.Months, tt.months)
+			}
+			if got.CashFlow {
+				t.Errorf("CashFlow = true, want the amortized view")
+			}
+		})
+	}
+}
+
+func TestPeriodWithView(t *testing.T) {
+	tests := []struct {
+		name          string
+		period        Period
+		view          string
+		wantView      string
+		wantIsMonthly bool
+		wantErr       bool
+	}{
+		{name: "amortized", period: Monthly, view: ViewAmortized, wantView: ViewAmortized, wantIsMonthly: true},
+		{name: "default view", period: Monthly, wantView: ViewAmortized, wantIsMonthly: true},
+		{name: "cash flow", period: Monthly, view: ViewCashFlow, wantView: ViewCashFlow},
+		{name: "amortized resets the cash flow", period: Period{Name: PeriodMonthly, CashFlow: true}, view: ViewAmortized, wantView: ViewAmortized, wantIsMonthly: true},
+		{name: "unnamed period", period: Period{}, wantView: ViewAmortized, wantIsMonthly: true},
+		{name: "yearly", period: Period{Name: PeriodYearly}, view: ViewAmortized, wantView: ViewAmortized},
+		{name: "unsupported view", period: Monthly, view: "cashflow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.period.WithView(tt.view)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("WithView(%q) error = nil, want an error", tt.view)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WithView(%q) error = %v", tt.view, err)
+			}
+			if got.View() != tt.wantView {
+				t.Errorf("View() = %s, want %s", got.View(), tt.wantView)
+			}
+			if got.IsMonthly() != tt.wantIsMonthly {
+				t.Errorf("IsMonthly() = %t, want %t", got.IsMonthly(), tt.wantIsMonthly)
+			}
+		})
+	}
+}
+
+func TestPeriodTitle(t *testing.T) {
+	tests := []struct {
+		period string
+		months int
+		view   string
+		want   string
+	}{
+		{period: PeriodHourly, view: ViewAmortized, want: "Hourly Cost"},
+		{period: PeriodDaily, view: ViewAmortized, want: "Daily Cost"},
+		{period: PeriodMonthly, view: ViewAmortized, want: "Monthly Cost"},
+		{period: PeriodYearly, view: ViewAmortized, want: "Yearly Cost"},
+		{months: 6, view: ViewAmortized, want: "Cost over 6 months"},
+		{period: PeriodHourly, view: ViewCashFlow, want: "Hourly Cash Flow"},
+		{period: PeriodDaily, view: ViewCashFlow, want: "Daily Cash Flow"},
+		{period: PeriodMonthly, view: ViewCashFlow, want: "Monthly Cash Flow"},
+		{period: PeriodYearly, view: ViewCashFlow, want: "Yearly Cash Flow"},
+		{months: 36, view: ViewCashFlow, want: "Cash Flow over 36 months"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := mustPeriod(t, tt.period, tt.months, tt.view).Title(); got != tt.want {
+				t.Errorf("Title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodConvert(t *testing.T) {
+	tests := []struct {
+		name          string
+		period        string
+		months        int
+		monthly       string
+		want          string
+		wantPrecision int
+	}{
+		{name: "hourly", period: PeriodHourly, monthly: "73", want: "0.1", wantPrecision: 4},
+		{name: "daily", period: PeriodDaily, monthly: "73", want: "2.4", wantPrecision: 2},
+		{name: "monthly", period: PeriodMonthly, monthly: "73", want: "73", wantPrecision: 2},
+		{name: "yearly", period: PeriodYearly, monthly: "73", want: "876", wantPrecision: 2},
+		{name: "months", months: 3, monthly: "73", want: "219", wantPrecision: 2},
+		{name: "negative", period: PeriodYearly, monthly: "-10", want: "-120", wantPrecision: 2},
+		{name: "zero", period: PeriodDaily, monthly: "0", want: "0", wantPrecision: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := mustPeriod(t, tt.period, tt.months, ViewAmortized)
+			monthly, want := decimal.RequireFromString(tt.monthly), decimal.RequireFromString(tt.want)
+			if got := p.ConvertDecimal(monthly); !got.Equal(want) {
+				t.Errorf("ConvertDecimal(%s) = %s, want %s", monthly, got, want)
+			}
+			got := p.Convert(Cost{Decimal: monthly, Currency: "EUR"})
+			if !got.Decimal.Equal(want) || got.Currency != "EUR" {
+				t.Errorf("Convert(%s EUR) = %s %s, want %s EUR", monthly, got.Decimal, got.Currency, want)
+			}
+			if got := p.Precision(); got != tt.wantPrecision {
+				t.Errorf("Precision() = %d, want %d", got, tt.wantPrecision)
+			}
+		})
+	}
+
+	// a period without hours keeps the monthly amount
+	if got := (Period{}).ConvertDecimal(decimal.NewFromInt(73)); !got.Equal(decimal.NewFromInt(73)) {
+		t.Errorf("ConvertDecimal() without hours = %s, want 73", got)
+	}
+}
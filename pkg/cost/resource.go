@@ -3,6 +3,7 @@ package cost
 import (
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kaytu-io/pennywise/pkg/usage"
 )
 
 // Resource represents costs of a single cloud resource. Each Resource includes a Component map, keyed
@@ -11,9 +12,13 @@ type Resource struct {
 	Address     string
 	Provider    string
 	Type        string
+	Region      string
+	Tags        map[string]string
 	Components  map[string][]Component
 	Skipped     bool
 	IsSupported bool
+	// Usage are the usage keys of the resource with their sources, set from its definition
+	Usage []usage.Value
 }
 
 // Cost returns the sum of costs of every Component of this Resource.
@@ -34,14 +39,21 @@ func (re Resource) Cost() (Cost, error) {
 }
 
 // CostRows returns rows for resource components
-// containing the components costs and total cost for the resource
-func (re Resource) CostRows() ([]table.Row, error) {
+// containing the components costs for the period and total cost for the resource
+func (re Resource) CostRows(p Period) ([]table.Row, error) {
 	var rows []table.Row
 
 	for _, comps := range re.Components {
 		for _, c := range comps {
+			name := c.Name
+			if !c.UpfrontQuantity.IsZero() {
+				name = fmt.Sprintf("%s (upfront qty %s %s)", name, c.UpfrontQuantity, c.UpfrontTerm())
+			}
+			if !c.IsPriced() {
+				name = fmt.Sprintf("%s %s (%v)", name, UnpricedMark, c.Error)
+			}
 			var row table.Row
-			row = append(row, faint.Sprint("└─ ")+c.Name, c.Rate.Decimal, c.HourlyQuantity, c.MonthlyQuantity, c.Unit, c.Cost().Decimal)
+			row = append(row, faint.Sprint("└─ ")+name, c.Rate.Decimal, c.HourlyQuantity, c.MonthlyQuantity, c.Unit, c.CostForPeriod(p).Decimal)
 			rows = append(rows, row)
 		}
 	}
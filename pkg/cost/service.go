@@ -0,0 +1,140 @@
+package cost
+
+import "strings"
+
+// serviceFamilies maps the prefixes of the resource types to their service family
+var serviceFamilies = map[string]string{
+	// AWS
+	"aws_instance":                "Compute",
+	"aws_autoscaling_group":       "Compute",
+	"aws_launch_template":         "Compute",
+	"aws_launch_configuration":    "Compute",
+	"aws_ecs_":                    "Compute",
+	"aws_eks_":                    "Compute",
+	"aws_lambda_":                 "Compute",
+	"aws_lightsail_":              "Compute",
+	"aws_batch_":                  "Compute",
+	"aws_ebs_":                    "Storage",
+	"aws_s3_":                     "Storage",
+	"aws_efs_":                    "Storage",
+	"aws_fsx_":                    "Storage",
+	"aws_backup_":                 "Storage",
+	"aws_glacier_":                "Storage",
+	"aws_db_":                     "Database",
+	"aws_rds_":                    "Database",
+	"aws_dynamodb_":               "Database",
+	"aws_elasticache_":            "Database",
+	"aws_redshift_":               "Database",
+	"aws_docdb_":                  "Database",
+	"aws_neptune_":                "Database",
+	"aws_dms_":                    "Database",
+	"aws_nat_gateway":             "Networking",
+	"aws_lb":                      "Networking",
+	"aws_alb":                     "Networking",
+	"aws_elb":                     "Networking",
+	"aws_eip":                     "Networking",
+	"aws_vpc_":                    "Networking",
+	"aws_vpn_":                    "Networking",
+	"aws_route53_":                "Networking",
+	"aws_cloudfront_":             "Networking",
+	"aws_ec2_transit_gateway":     "Networking",
+	"aws_dx_":                     "Networking",
+	"aws_globalaccelerator_":      "Networking",
+	"aws_networkfirewall_":        "Networking",
+	"aws_kinesis":                 "Analytics",
+	"aws_msk_":                    "Analytics",
+	"aws_elasticsearch_":          "Analytics",
+	"aws_opensearch_":             "Analytics",
+	"aws_glue_":                   "Analytics",
+	"aws_athena_":                 "Analytics",
+	"aws_emr_":                    "Analytics",
+	"aws_cloudwatch_":             "Management",
+	"aws_config_":                 "Management",
+	"aws_cloudtrail":              "Management",
+	"aws_ssm_":                    "Management",
+	"aws_kms_":                    "Security",
+	"aws_secretsmanager_":         "Security",
+	"aws_acm_":                    "Security",
+	"aws_waf":                     "Security",
+	"aws_guardduty_":              "Security",
+	"aws_sqs_":                    "Integration",
+	"aws_sns_":                    "Integration",
+	"aws_sfn_":                    "Integration",
+	"aws_mq_":                     "Integration",
+	"aws_api_gateway_":            "Integration",
+	"aws_apigatewayv2_":           "Integration",
+	"aws_ecr_":                    "Containers",
+	"aws_mwaa_":                   "Analytics",
+	"aws_transfer_":               "Storage",
+	"aws_cloudformation_":         "Management",
+	"aws_codebuild_":              "Developer Tools",
+	"aws_directory_service_":      "Security",
+	"aws_sagemaker_":              "Machine Learning",
+	"aws_ec2_client_vpn_endpoint": "Networking",
+
+	// Azure
+	"azurerm_virtual_machine":         "Compute",
+	"azurerm_linux_virtual_machine":   "Compute",
+	"azurerm_windows_virtual_machine": "Compute",
+	"azurerm_kubernetes_":             "Compute",
+	"azurerm_app_service":             "Compute",
+	"azurerm_function_app":            "Compute",
+	"azurerm_linux_function_app":      "Compute",
+	"azurerm_windows_function_app":    "Compute",
+	"azurerm_linux_web_app":           "Compute",
+	"azurerm_windows_web_app":         "Compute",
+	"azurerm_service_plan":            "Compute",
+	"azurerm_container_":              "Compute",
+	"azurerm_managed_disk":            "Storage",
+	"azurerm_storage_":                "Storage",
+	"azurerm_snapshot":                "Storage",
+	"azurerm_backup_":                 "Storage",
+	"azurerm_mssql_":                  "Database",
+	"azurerm_sql_":                    "Database",
+	"azurerm_mysql_":                  "Database",
+	"azurerm_postgresql_":             "Database",
+	"azurerm_mariadb_":                "Database",
+	"azurerm_cosmosdb_":               "Database",
+	"azurerm_redis_":                  "Database",
+	"azurerm_public_ip":               "Networking",
+	"azurerm_nat_gateway":             "Networking",
+	"azurerm_lb":                      "Networking",
+	"azurerm_application_gateway":     "Networking",
+	"azurerm_virtual_network_gateway": "Networking",
+	"azurerm_dns_":                    "Networking",
+	"azurerm_private_dns_":            "Networking",
+	"azurerm_firewall":                "Networking",
+	"azurerm_frontdoor":               "Networking",
+	"azurerm_cdn_":                    "Networking",
+	"azurerm_express_route_":          "Networking",
+	"azurerm_private_endpoint":        "Networking",
+	"azurerm_traffic_manager_":        "Networking",
+	"azurerm_eventhub":                "Analytics",
+	"azurerm_data_factory":            "Analytics",
+	"azurerm_synapse_":                "Analytics",
+	"azurerm_databricks_":             "Analytics",
+	"azurerm_hdinsight_":              "Analytics",
+	"azurerm_monitor_":                "Management",
+	"azurerm_log_analytics_":          "Management",
+	"azurerm_application_insights":    "Management",
+	"azurerm_automation_":             "Management",
+	"azurerm_key_vault":               "Security",
+	"azurerm_servicebus_":             "Integration",
+	"azurerm_api_management":          "Integration",
+	"azurerm_eventgrid_":              "Integration",
+	"azurerm_logic_app_":              "Integration",
+}
+
+// ServiceFamily returns the service family of the resource type, like Compute, Storage or Database.
+// The longest matching prefix is used and Other is returned for the unknown types.
+func ServiceFamily(resourceType string) string {
+	family := "Other"
+	var longestPrefix int
+	for prefix, f := range serviceFamilies {
+		if len(prefix) > longestPrefix && strings.HasPrefix(resourceType, prefix) {
+			family = f
+			longestPrefix = len(prefix)
+		}
+	}
+	return family
+}
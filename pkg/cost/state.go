@@ -5,6 +5,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kaytu-io/pennywise/pkg/usage"
 	"sort"
 )
 
@@ -39,6 +40,24 @@ func getModuleResources(state ModularState) map[string]Resource {
 	return resources
 }
 
+// ResourcesByAddress returns the resources of the state and its child modules keyed by their address
+func (s *ModularState) ResourcesByAddress() map[string]Resource {
+	resources := make(map[string]Resource)
+	for name, res := range s.Resources {
+		address := res.Address
+		if address == "" {
+			address = name
+		}
+		resources[address] = res
+	}
+	for _, child := range s.ChildModules {
+		for address, res := range child.ResourcesByAddress() {
+			resources[address] = res
+		}
+	}
+	return resources
+}
+
 func (s *ModularState) TotalResourcesCount() int {
 	return resourcesCount(*s)
 }
@@ -128,8 +147,8 @@ func (s *State) GetCostComponents() []Component {
 }
 
 // CostString returns a string to show the breakdown of the costs for a state
-// containing the resources and their components costs and total cost for the resources and the state
-func (s *State) CostString() (string, error) {
+// containing the resources and their components costs and total cost for the resources and the state for the period
+func (s *State) CostString(p Period) (string, error) {
 	var costString string
 
 	t := table.NewWriter()
@@ -180,7 +199,7 @@ func (s *State) CostString() (string, error) {
 	})
 	i++
 
-	headers = append(headers, underline.Sprint("Monthly Cost"))
+	headers = append(headers, underline.Sprint(p.Title()))
 	columns = append(columns, table.ColumnConfig{
 		Number:      i,
 		Align:       text.AlignRight,
@@ -194,10 +213,12 @@ func (s *State) CostString() (string, error) {
 	t.AppendHeader(headers)
 
 	var unsupportedServices []string
-	cost, err := s.Cost()
+	var unpricedCount int
+	cost, err := s.CostForPeriod(p)
 	if err != nil {
 		return "", err
 	}
+	ac := MoneyFormatter(cost.Currency, p.Precision())
 	resources := getSortedResources(s.Resources)
 
 	for _, rs := range resources {
@@ -205,13 +226,14 @@ func (s *State) CostString() (string, error) {
 			unsupportedServices = append(unsupportedServices, rs.Type)
 			continue
 		}
-		cost, err := rs.Cost()
+		cost, err := rs.CostForPeriod(p)
 		if err != nil {
 			return "", err
 		}
+		unpricedCount += len(rs.UnpricedComponents(rs.Address))
 		var row table.Row
-		row = append(row, bold.Sprint(rs.Address), "", "", "", "", cost.Decimal.Round(2))
-		costRows, err := rs.CostRows()
+		row = append(row, bold.Sprint(rs.Address), "", "", "", "", ac.FormatMoney(cost.Decimal))
+		costRows, err := rs.CostRows(p)
 		if err != nil {
 			return "", err
 		}
@@ -223,7 +245,21 @@ func (s *State) CostString() (string, error) {
 
 	costString = t.Render()
 	costString += "\n──────────────────────────────────\n"
-	costString += fmt.Sprintf("%s:    %v", bold.Sprint("Total Cost (per month)"), cost.Decimal.Round(2))
+	totalTitle := "Total Cost (per month)"
+	if !p.IsMonthly() {
+		totalTitle = fmt.Sprintf("Total %s", p.Title())
+	}
+	costString += fmt.Sprintf("%s:    %s", bold.Sprint(totalTitle), ac.FormatMoney(cost.Decimal))
+	upfront, _, err := resourcesUpfrontCosts(s.Resources)
+	if err != nil {
+		return "", err
+	}
+	if upfrontString := UpfrontString(upfront, p); upfrontString != "" {
+		costString += "\n" + upfrontString
+	}
+	if unpricedCount > 0 {
+		costString = fmt.Sprintf("%s\n- %s %d components could not be priced", costString, UnpricedMark, unpricedCount)
+	}
 	if len(unsupportedServices) == 3 {
 		costString = fmt.Sprintf("%s\n- Resource types %s, %s and %s not supported", costString, unsupportedServices[0], unsupportedServices[1], unsupportedServices[2])
 	} else if len(unsupportedServices) == 2 {
@@ -277,3 +313,32 @@ func getSortedResources(resourcesMap map[string]Resource) []Resource {
 	})
 	return resources
 }
+
+// ResourceAttributes are the attributes of a resource read from its definition which are not returned by the server
+type ResourceAttributes struct {
+	Region string
+	Tags   map[string]string
+	Usage  []usage.Value
+}
+
+// SetAttributes sets the region, tags and usage keys of the resources from the attributes map keyed by the resource address,
+// and the upfront payments of the reserved instances by their usage
+func (s *ModularState) SetAttributes(attributes map[string]ResourceAttributes) {
+	for name, res := range s.Resources {
+		address := res.Address
+		if address == "" {
+			address = name
+		}
+		if attrs, ok := attributes[address]; ok {
+			res.Region = attrs.Region
+			res.Tags = attrs.Tags
+			res.Usage = attrs.Usage
+			res.setReservedUpfront()
+			s.Resources[name] = res
+		}
+	}
+	for name, child := range s.ChildModules {
+		child.SetAttributes(attributes)
+		s.ChildModules[name] = child
+	}
+}
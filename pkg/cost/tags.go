@@ -0,0 +1,74 @@
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagViolation is a resource with a cost missing some of the required tags
+type TagViolation struct {
+	Address     string
+	MissingTags []string
+	Cost        Cost
+}
+
+// MissingTags returns the resources with a non-zero cost that don't have all the required tags.
+// The violations are sorted by their cost.
+func (s *ModularState) MissingTags(requiredTags []string) ([]TagViolation, error) {
+	var violations []TagViolation
+	err := moduleMissingTags(*s, "", requiredTags, &violations)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if !violations[i].Cost.Decimal.Equal(violations[j].Cost.Decimal) {
+			return violations[i].Cost.Decimal.GreaterThan(violations[j].Cost.Decimal)
+		}
+		return violations[i].Address < violations[j].Address
+	})
+	return violations, nil
+}
+
+func moduleMissingTags(state ModularState, path string, requiredTags []string, violations *[]TagViolation) error {
+	for name, res := range state.Resources {
+		address := res.Address
+		if address == "" {
+			address = joinAddress(path, name)
+		}
+		resCost, err := res.Cost()
+		if err != nil {
+			return fmt.Errorf("failed to get cost of resource %s: %w", address, err)
+		}
+		if resCost.Decimal.IsZero() {
+			continue
+		}
+
+		var missing []string
+		for _, key := range requiredTags {
+			if res.Tags[key] == "" {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			*violations = append(*violations, TagViolation{Address: address, MissingTags: missing, Cost: resCost})
+		}
+	}
+	for name, child := range state.ChildModules {
+		err := moduleMissingTags(child, joinAddress(path, name), requiredTags, violations)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagViolationsString returns a string to show the resources missing the required tags
+func TagViolationsString(violations []TagViolation) string {
+	var sb strings.Builder
+	sb.WriteString(bold.Sprintf("%d resources with costs are missing required tags:", len(violations)))
+	for _, v := range violations {
+		sb.WriteString(fmt.Sprintf("\n- %s (%s per month): %s", v.Address, MoneyFormatter(v.Cost.Currency, 2).FormatMoney(v.Cost.Decimal), strings.Join(v.MissingTags, ", ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UnpricedMark is shown next to the components that their price could not be found
+const UnpricedMark = "⚠"
+
+// UnpricedComponent is a component of a resource that its price could not be found
+type UnpricedComponent struct {
+	Address   string
+	Label     string
+	Component Component
+}
+
+// UnpricedComponents returns the components with a pricing error in the state and its child modules
+// sorted by the resource address and the component name.
+func (s *ModularState) UnpricedComponents() []UnpricedComponent {
+	var unpriced []UnpricedComponent
+	moduleUnpricedComponents(*s, "", &unpriced)
+	sort.SliceStable(unpriced, func(i, j int) bool {
+		if unpriced[i].Address != unpriced[j].Address {
+			return unpriced[i].Address < unpriced[j].Address
+		}
+		return unpriced[i].Component.Name < unpriced[j].Component.Name
+	})
+	return unpriced
+}
+
+func moduleUnpricedComponents(state ModularState, path string, unpriced *[]UnpricedComponent) {
+	for name, res := range state.Resources {
+		address := res.Address
+		if address == "" {
+			address = joinAddress(path, name)
+		}
+		*unpriced = append(*unpriced, res.UnpricedComponents(address)...)
+	}
+	for name, child := range state.ChildModules {
+		moduleUnpricedComponents(child, joinAddress(path, name), unpriced)
+	}
+}
+
+// UnpricedComponents returns the components of the resource with a pricing error
+func (re Resource) UnpricedComponents(address string) []UnpricedComponent {
+	var unpriced []UnpricedComponent
+	for label, comps := range re.Components {
+		for _, c := range comps {
+			if !c.IsPriced() {
+				unpriced = append(unpriced, UnpricedComponent{Address: address, Label: label, Component: c})
+			}
+		}
+	}
+	return unpriced
+}
+
+// UnpricedString returns a string to show the components that their price could not be found
+func UnpricedString(unpriced []UnpricedComponent) string {
+	var sb strings.Builder
+	sb.WriteString(bold.Sprintf("%s %d components could not be priced and are not included in the costs:", UnpricedMark, len(unpriced)))
+	for _, u := range unpriced {
+		sb.WriteString(fmt.Sprintf("\n- %s: %s (%v)", u.Address, u.Component.Name, u.Component.Error))
+	}
+	return sb.String()
+}
+
+// UnpricedError returns an error if there are components that their price could not be found
+func UnpricedError(count int) error {
+	if count == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d components could not be priced", count)
+}
@@ -0,0 +1,228 @@
+package cost
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/usage"
+	"github.com/shopspring/decimal"
+)
+
+// reservedUpfrontShares are the shares of the cost of a reserved instance term paid upfront by the payment option.
+// The actual share of a partial upfront payment depends on the offering, it's estimated as half of the cost of the term.
+var reservedUpfrontShares = map[string]decimal.Decimal{
+	"partial_upfront": decimal.NewFromFloat(0.5),
+	"all_upfront":     decimal.NewFromInt(1),
+}
+
+// reservedTermMonths are the months of the reserved instance terms
+var reservedTermMonths = map[string]int{
+	"1_year": 12,
+	"3_year": 36,
+}
+
+// ReservedUpfront returns the component priced with the reserved purchase option with the upfront payment of its hours
+// estimated by the term and the payment option in the usage of the resource. The upfront payment priced by the server
+// is kept as it is. The reserved instance hours are priced at the effective rate with the upfront payment spread over
+// the term, so the upfront share of the hours is moved to the quantity paid once for the term: the amortized costs stay
+// the same while the cash-flow view shows the payment upfront.
+func ReservedUpfront(c Component, values []usage.Value) Component {
+	if c.PurchaseOption != PurchaseOptionReserved || !c.UpfrontQuantity.IsZero() || c.HourlyQuantity.IsZero() {
+		return c
+	}
+	var term, paymentOption string
+	for _, v := range values {
+		switch v.Key {
+		case usage.ReservedInstanceTermKey:
+			term = fmt.Sprint(v.Value)
+		case usage.ReservedInstancePaymentOptionKey:
+			paymentOption = fmt.Sprint(v.Value)
+		}
+	}
+	share, ok := reservedUpfrontShares[paymentOption]
+	if !ok {
+		return c
+	}
+	months, ok := reservedTermMonths[term]
+	if !ok {
+		return c
+	}
+	upfrontHours := c.HourlyQuantity.Mul(share)
+	c.HourlyQuantity = c.HourlyQuantity.Sub(upfrontHours)
+	c.UpfrontQuantity = upfrontHours.Mul(HoursPerMonth).Mul(decimal.NewFromInt(int64(months)))
+	c.UpfrontMonths = months
+	c.UpfrontEstimated = true
+	return c
+}
+
+// UpfrontTerm describes the months the upfront payment of the component is amortized over
+// and whether it's estimated from the payment option instead of being priced
+func (c Component) UpfrontTerm() string {
+	term := fmt.Sprintf("over %d months", c.Months())
+	if c.UpfrontEstimated {
+		term += ", estimated from the payment option"
+	}
+	return term
+}
+
+// setReservedUpfront sets the upfront payments of the reserved instance hours of the resource from its usage
+func (re *Resource) setReservedUpfront() {
+	for name, comps := range re.Components {
+		for i, c := range comps {
+			comps[i] = ReservedUpfront(c, re.Usage)
+		}
+		re.Components[name] = comps
+	}
+}
+
+// UpfrontCosts returns the sum of the one-time upfront costs of every Component of the Resource
+// and the sum of their monthly amortized shares.
+func (re Resource) UpfrontCosts() (Cost, Cost, error) {
+	var upfront, amortized Cost
+	var err error
+	for name, comp := range re.Components {
+		for _, c := range comp {
+			upfront, err = upfront.Add(c.UpfrontCost())
+			if err != nil {
+				return Zero, Zero, fmt.Errorf("failed to add upfront cost of component %s: %w", name, err)
+			}
+			amortized, err = amortized.Add(c.AmortizedUpfrontCost())
+			if err != nil {
+				return Zero, Zero, fmt.Errorf("failed to add upfront cost of component %s: %w", name, err)
+			}
+		}
+	}
+	return upfront, amortized, nil
+}
+
+// UpfrontCosts returns the sum of the one-time upfront costs of the module and its child modules
+// and the sum of their monthly amortized shares.
+func (s *ModularState) UpfrontCosts() (Cost, Cost, error) {
+	upfront, amortized, err := resourcesUpfrontCosts(s.Resources)
+	if err != nil {
+		return Zero, Zero, err
+	}
+	for name, child := range s.ChildModules {
+		childUpfront, childAmortized, err := child.UpfrontCosts()
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to get upfront cost of module %s: %w", name, err)
+		}
+		upfront, err = upfront.Add(childUpfront)
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to add upfront cost of module %s: %w", name, err)
+		}
+		amortized, err = amortized.Add(childAmortized)
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to add upfront cost of module %s: %w", name, err)
+		}
+	}
+	return upfront, amortized, nil
+}
+
+func resourcesUpfrontCosts(resources map[string]Resource) (Cost, Cost, error) {
+	var upfront, amortized Cost
+	for name, res := range resources {
+		resUpfront, resAmortized, err := res.UpfrontCosts()
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to get upfront cost of resource %s: %w", name, err)
+		}
+		upfront, err = upfront.Add(resUpfront)
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to add upfront cost of resource %s: %w", name, err)
+		}
+		amortized, err = amortized.Add(resAmortized)
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to add upfront cost of resource %s: %w", name, err)
+		}
+	}
+	return upfront, amortized, nil
+}
+
+// UpfrontPayments are the one-time upfront payments keyed by the number of months of their terms
+type UpfrontPayments map[int]Cost
+
+// add adds the upfront payment of a term of the months
+func (u UpfrontPayments) add(months int, c Cost) error {
+	if c.IsZero() {
+		return nil
+	}
+	sum, err := u[months].Add(c)
+	if err != nil {
+		return err
+	}
+	u[months] = sum
+	return nil
+}
+
+// merge adds the upfront payments of other
+func (u UpfrontPayments) merge(other UpfrontPayments) error {
+	for months, c := range other {
+		if err := u.add(months, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpfrontPayments returns the upfront payment of the component by the months of its term
+func (c Component) UpfrontPayments() UpfrontPayments {
+	payments := make(UpfrontPayments)
+	payments.add(c.Months(), c.UpfrontCost())
+	return payments
+}
+
+// UpfrontPayments returns the sum of the upfront payments of every Component of the Resource by the months of their terms
+func (re Resource) UpfrontPayments() (UpfrontPayments, error) {
+	payments := make(UpfrontPayments)
+	for name, comp := range re.Components {
+		for _, c := range comp {
+			if err := payments.add(c.Months(), c.UpfrontCost()); err != nil {
+				return nil, fmt.Errorf("failed to add upfront cost of component %s: %w", name, err)
+			}
+		}
+	}
+	return payments, nil
+}
+
+// UpfrontPayments returns the sum of the upfront payments of the module and its child modules by the months of their terms
+func (s *ModularState) UpfrontPayments() (UpfrontPayments, error) {
+	payments, err := resourcesUpfrontPayments(s.Resources)
+	if err != nil {
+		return nil, err
+	}
+	for name, child := range s.ChildModules {
+		childPayments, err := child.UpfrontPayments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get upfront cost of module %s: %w", name, err)
+		}
+		if err := payments.merge(childPayments); err != nil {
+			return nil, fmt.Errorf("failed to add upfront cost of module %s: %w", name, err)
+		}
+	}
+	return payments, nil
+}
+
+func resourcesUpfrontPayments(resources map[string]Resource) (UpfrontPayments, error) {
+	payments := make(UpfrontPayments)
+	for name, res := range resources {
+		resPayments, err := res.UpfrontPayments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get upfront cost of resource %s: %w", name, err)
+		}
+		if err := payments.merge(resPayments); err != nil {
+			return nil, fmt.Errorf("failed to add upfront cost of resource %s: %w", name, err)
+		}
+	}
+	return payments, nil
+}
+
+// UpfrontString returns a line to show the one-time upfront cost and how it's included in the costs of the period,
+// it's empty if there are no upfront costs
+func UpfrontString(upfront Cost, p Period) string {
+	if upfront.IsZero() {
+		return ""
+	}
+	ac := MoneyFormatter(upfront.Currency, 2)
+	if p.CashFlow {
+		return fmt.Sprintf("%s:    %s, paid at the start of each term in the costs", bold.Sprint("Upfront Cost (one-time)"), ac.FormatMoney(upfront.Decimal))
+	}
+	return fmt.Sprintf("%s:    %s, amortized over its term in the costs", bold.Sprint("Upfront Cost (one-time)"), ac.FormatMoney(upfront.Decimal))
+}
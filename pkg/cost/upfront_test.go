@@ -0,0 +1,161 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/pennywise/pkg/usage"
+	"github.com/shopspring/decimal"
+)
+
+// reservedUsage returns the usage values of a reserved instance with the term and the payment option
+func reservedUsage(term, paymentOption string) []usage.Value {
+	return []usage.Value{
+		{Key: usage.ReservedInstanceTermKey, Value: term},
+		{Key: usage.ReservedInstancePaymentOptionKey, Value: paymentOption},
+	}
+}
+
+func TestReservedUpfront(t *testing.T) {
+	reserved := Component{
+		Name:           "Compute (Linux, m5.large)",
+		HourlyQuantity: decimal.NewFromInt(1),
+		Rate:           Cost{Decimal: decimal.NewFromFloat(0.06), Currency: "USD"},
+		PurchaseOption: PurchaseOptionReserved,
+	}
+	withPurchaseOption := func(option string) Component {
+		c := reserved
+		c.PurchaseOption = option
+		return c
+	}
+	priced := reserved
+	priced.UpfrontQuantity = decimal.NewFromInt(300)
+	priced.UpfrontMonths = 36
+
+	tests := []struct {
+		name          string
+		component     Component
+		values        []usage.Value
+		wantHourly    string
+		wantUpfront   string
+		wantMonths    int
+		wantEstimated bool
+	}{
+		{
+			name: "partial upfront for 1 year", component: reserved, values: reservedUsage("1_year", "partial_upfront"),
+			wantHourly: "0.5", wantUpfront: "4380", wantMonths: 12, wantEstimated: true,
+		},
+		{
+			name: "all upfront for 3 years", component: reserved, values: reservedUsage("3_year", "all_upfront"),
+			wantHourly: "0", wantUpfront: "26280", wantMonths: 36, wantEstimated: true,
+		},
+		{
+			name: "no upfront", component: reserved, values: reservedUsage("1_year", "no_upfront"),
+			wantHourly: "1", wantUpfront: "0",
+		},
+		{
+			name: "unknown term", component: reserved, values: reservedUsage("2_year", "all_upfront"),
+			wantHourly: "1", wantUpfront: "0",
+		},
+		{
+			name: "no usage", component: reserved,
+			wantHourly: "1", wantUpfront: "0",
+		},
+		{
+			name: "on-demand component named reserved", values: reservedUsage("1_year", "all_upfront"),
+			component:  Component{Name: "Reserved capacity", HourlyQuantity: decimal.NewFromInt(1), PurchaseOption: PurchaseOptionOnDemand},
+			wantHourly: "1", wantUpfront: "0",
+		},
+		{
+			name: "no purchase option", component: withPurchaseOption(""), values: reservedUsage("1_year", "all_upfront"),
+			wantHourly: "1", wantUpfront: "0",
+		},
+		{
+			name: "upfront priced by the server", component: priced, values: reservedUsage("1_year", "all_upfront"),
+			wantHourly: "1", wantUpfront: "300", wantMonths: 36,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReservedUpfront(tt.component, tt.values)
+			if want := decimal.RequireFromString(tt.wantHourly); !got.HourlyQuantity.Equal(want) {
+				t.Errorf("HourlyQuantity = %s, want %s", got.HourlyQuantity, want)
+			}
+			if want := decimal.RequireFromString(tt.wantUpfront); !got.UpfrontQuantity.Equal(want) {
+				t.Errorf("UpfrontQuantity = %s, want %s", got.UpfrontQuantity, want)
+			}
+			if got.UpfrontMonths != tt.wantMonths {
+				t.Errorf("UpfrontMonths = %d, want %d", got.UpfrontMonths, tt.wantMonths)
+			}
+			if got.UpfrontEstimated != tt.wantEstimated {
+				t.Errorf("UpfrontEstimated = %t, want %t", got.UpfrontEstimated, tt.wantEstimated)
+			}
+			// moving the hours to the upfront payment keeps the amortized cost
+			if tt.wantEstimated && !got.Cost().Decimal.Equal(tt.component.Cost().Decimal) {
+				t.Errorf("Cost() = %s, want the cost before the upfront payment %s", got.Cost().Decimal, tt.component.Cost().Decimal)
+			}
+		})
+	}
+}
+
+func TestComponentUpfrontTerm(t *testing.T) {
+	tests := []struct {
+		name      string
+		component Component
+		want      string
+	}{
+		{name: "priced", component: Component{UpfrontMonths: 36}, want: "over 36 months"},
+		{name: "default term", component: Component{}, want: "over 12 months"},
+		{name: "estimated", component: Component{UpfrontMonths: 12, UpfrontEstimated: true}, want: "over 12 months, estimated from the payment option"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.component.UpfrontTerm(); got != tt.want {
+				t.Errorf("UpfrontTerm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceUpfrontPayments(t *testing.T) {
+	usd := func(f float64) Cost { return Cost{Decimal: decimal.NewFromFloat(f), Currency: "USD"} }
+	re := Resource{
+		Usage: reservedUsage("1_year", "all_upfront"),
+		Components: map[string][]Component{
+			"compute": {{Name: "Compute", HourlyQuantity: decimal.NewFromFloat(0.1), Rate: usd(1), PurchaseOption: PurchaseOptionReserved}},
+			"storage": {{Name: "Storage", MonthlyQuantity: decimal.NewFromInt(10), Rate: usd(0.1)}},
+			"license": {{Name: "License", UpfrontQuantity: decimal.NewFromInt(100), UpfrontMonths: 36, Rate: usd(1)}},
+		},
+	}
+	re.setReservedUpfront()
+	if c := re.Components["compute"][0]; !c.UpfrontEstimated || !c.HourlyQuantity.IsZero() {
+		t.Errorf("compute component = %+v, want its hours paid upfront", c)
+	}
+	if c := re.Components["storage"][0]; !c.UpfrontQuantity.IsZero() {
+		t.Errorf("storage UpfrontQuantity = %s, want 0", c.UpfrontQuantity)
+	}
+
+	payments, err := re.UpfrontPayments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]string{12: "876", 36: "100"}
+	if len(payments) != len(want) {
+		t.Errorf("UpfrontPayments() = %v, want %v", payments, want)
+	}
+	for months, w := range want {
+		if got := payments[months]; !got.Decimal.Equal(decimal.RequireFromString(w)) {
+			t.Errorf("UpfrontPayments()[%d] = %s, want %s", months, got.Decimal, w)
+		}
+	}
+
+	upfront, amortized, err := re.UpfrontCosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := decimal.NewFromInt(976); !upfront.Decimal.Equal(want) {
+		t.Errorf("UpfrontCosts() upfront = %s, want %s", upfront.Decimal, want)
+	}
+	if want := decimal.RequireFromString("75.7778"); !amortized.Decimal.Round(4).Equal(want) { // 876/12 + 100/36
+		t.Errorf("UpfrontCosts() amortized = %s, want %s", amortized.Decimal.Round(4), want)
+	}
+}
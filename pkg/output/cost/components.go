@@ -6,13 +6,22 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/usage"
 	"golang.org/x/crypto/ssh/terminal"
+	"sort"
+	"strings"
 )
 
 type ComponentsModel struct {
 	label          string
 	table          table.Model
 	resourcesModel ResourcesModel
+	// components are in the same order as the table rows
+	components []cost.Component
+	// usage are the usage keys of the resource with their sources
+	usage []usage.Value
+	// explaining shows how the cost of the selected component is calculated
+	explaining bool
 }
 
 func (m ComponentsModel) Init() tea.Cmd { return nil }
@@ -26,6 +35,9 @@ func (m ComponentsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "left":
 			return m.resourcesModel, cmd
+		case "e":
+			m.explaining = !m.explaining
+			return m, cmd
 		}
 	}
 	m.table, cmd = m.table.Update(msg)
@@ -33,18 +45,59 @@ func (m ComponentsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m ComponentsModel) View() string {
-	output := "Navigate to resources by pressing ← Quit by pressing Q or [CTRL+C]\n\n"
+	output := "Navigate to resources by pressing ← Explain the cost of a component by pressing E Quit by pressing Q or [CTRL+C]\n\n"
 	output += bold.Sprint(m.label) + "\n" + baseStyle.Render(m.table.View()) + "\n"
+	output += m.selectedComponentView()
 	output += "To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md"
 	return output
 }
 
-func getComponentsModel(resourceName, resourceCost string, components map[string][]cost.Component, resModel ResourcesModel) (tea.Model, error) {
+// selectedComponentView shows the pricing error and the details of the selected component
+func (m ComponentsModel) selectedComponentView() string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.components) {
+		return ""
+	}
+	c := m.components[cursor]
+	if m.explaining {
+		return m.explainView(c)
+	}
+	var output string
+	if !c.IsPriced() {
+		output += fmt.Sprintf("%s %s: %v, it's not included in the costs\n", cost.UnpricedMark, c.Name, c.Error)
+	}
+	if upfront := c.UpfrontCost(); !upfront.IsZero() {
+		output += fmt.Sprintf("Upfront Cost (one-time): %s, amortized %s\n",
+			m.resourcesModel.opts.accounting().FormatMoney(upfront.Decimal), c.UpfrontTerm())
+	}
+	if len(c.Details) > 0 {
+		output += faint.Sprintf("%s\n", strings.Join(c.Details, "\n"))
+	}
+	return output
+}
+
+// explainView shows how the monthly cost of the component is calculated and the usage keys of the resource
+func (m ComponentsModel) explainView(c cost.Component) string {
+	output := bold.Sprintf("%s\n", c.Name)
+	for _, line := range c.Explain() {
+		output += line + "\n"
+	}
+	if len(m.usage) > 0 {
+		output += bold.Sprint("Usage") + "\n"
+		for _, u := range m.usage {
+			output += fmt.Sprintf("%s = %v %s\n", u.Key, u.Value, faint.Sprintf("(%s)", u.Source))
+		}
+	}
+	return output
+}
+
+func getComponentsModel(resourceName, resourceCost string, resource cost.Resource, resModel ResourcesModel) (tea.Model, error) {
+	components := resource.Components
 	var longestName int
 	for _, comps := range components {
 		for _, c := range comps {
-			if len(c.Name) > longestName {
-				longestName = len(c.Name)
+			if len(c.Name)+2 > longestName {
+				longestName = len(c.Name) + 2
 			}
 		}
 	}
@@ -61,20 +114,28 @@ func getComponentsModel(resourceName, resourceCost string, components map[string
 		{Title: "Hourly Qty", Width: 12},
 		{Title: "Monthly Qty", Width: 12},
 		{Title: "Unit", Width: 14},
-		{Title: "Monthly Cost", Width: 15},
+		{Title: resModel.opts.period().Title(), Width: max(15, len(resModel.opts.period().Title()))},
 	}
 
-	var rows []table.Row
-
+	var sortedComponents []cost.Component
 	for _, comps := range components {
-		for _, c := range comps {
-			var row table.Row
-			row = append(row, c.Name, c.Rate.Decimal.String(), c.HourlyQuantity.String(), c.MonthlyQuantity.String(), c.Unit, c.Cost().Decimal.String())
-			rows = append(rows, row)
+		sortedComponents = append(sortedComponents, comps...)
+	}
+	sort.SliceStable(sortedComponents, func(i, j int) bool {
+		return sortedComponents[i].Cost().Decimal.GreaterThan(sortedComponents[j].Cost().Decimal)
+	})
+
+	var rows []table.Row
+	for _, c := range sortedComponents {
+		name := c.Name
+		if !c.IsPriced() {
+			name = cost.UnpricedMark + " " + name
 		}
+		var row table.Row
+		row = append(row, name, c.Rate.Decimal.String(), c.HourlyQuantity.String(), c.MonthlyQuantity.String(), c.Unit, c.CostForPeriod(resModel.opts.period()).Decimal.String())
+		rows = append(rows, row)
 	}
-	rows = sortRows(rows)
-	rows = makeNumbersAccounting(rows)
+	rows = makeNumbersAccounting(rows, resModel.opts.accounting())
 
 	t := table.New(
 		table.WithColumns(columns),
@@ -96,6 +157,6 @@ func getComponentsModel(resourceName, resourceCost string, components map[string
 		BorderForeground(lipgloss.Color("240")).
 		BorderLeft(true).BorderBottom(false).BorderRight(false).BorderTop(false)
 	t.SetStyles(s)
-	m := ComponentsModel{fmt.Sprintf("%s, Resource Total Cost: %s", resourceName, resourceCost), t, resModel}
+	m := ComponentsModel{fmt.Sprintf("%s, Resource Total Cost: %s", resourceName, resourceCost), t, resModel, sortedComponents, resource.Usage, false}
 	return m, nil
 }
@@ -0,0 +1,140 @@
+package cost
+
+import (
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"sort"
+	"strings"
+)
+
+// sortMode is the order of the rows in the resources table
+type sortMode int
+
+const (
+	sortByCost sortMode = iota
+	sortByName
+	sortByResourcesCount
+)
+
+func (s sortMode) next() sortMode {
+	return (s + 1) % 3
+}
+
+func (s sortMode) String() string {
+	switch s {
+	case sortByName:
+		return "name"
+	case sortByResourcesCount:
+		return "resources count"
+	default:
+		return "cost"
+	}
+}
+
+// resourcesFilter narrows down the rows of the resources table,
+// empty fields match everything
+type resourcesFilter struct {
+	query        string
+	provider     string
+	resourceType string
+	region       string
+}
+
+func (f resourcesFilter) isActive() bool {
+	return f != resourcesFilter{}
+}
+
+// matchAttributes checks the provider, type and region of the resource
+func (f resourcesFilter) matchAttributes(res cost.Resource) bool {
+	return (f.provider == "" || res.Provider == f.provider) &&
+		(f.resourceType == "" || res.Type == f.resourceType) &&
+		(f.region == "" || res.Region == f.region)
+}
+
+func (f resourcesFilter) matchResource(name string, res cost.Resource) bool {
+	return fuzzyMatch(f.query, name) && f.matchAttributes(res)
+}
+
+// matchModule checks if the module name matches the search query or it contains a matching resource
+func (f resourcesFilter) matchModule(name string, module cost.ModularState) bool {
+	attributesFilter := f
+	attributesFilter.query = ""
+	if fuzzyMatch(f.query, name) && (!attributesFilter.isActive() || anyResource(module, attributesFilter)) {
+		return true
+	}
+	return anyResource(module, f)
+}
+
+// anyResource checks if the module or its child modules contain a resource matching the filter
+func anyResource(module cost.ModularState, f resourcesFilter) bool {
+	for name, res := range module.Resources {
+		if f.matchResource(name, res) {
+			return true
+		}
+	}
+	for _, child := range module.ChildModules {
+		if anyResource(child, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyMatch checks if the characters of the query appear in the name in the same order, ignoring case and spaces
+func fuzzyMatch(query, name string) bool {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	i := 0
+	for _, r := range strings.ToLower(name) {
+		if i < len(q) && r == q[i] {
+			i++
+		}
+	}
+	return i == len(q)
+}
+
+// filterValues returns the distinct values of the resources in the module and its child modules
+func filterValues(module cost.ModularState, value func(cost.Resource) string) []string {
+	set := make(map[string]bool)
+	collectFilterValues(module, value, set)
+	var values []string
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func collectFilterValues(module cost.ModularState, value func(cost.Resource) string, set map[string]bool) {
+	for _, res := range module.Resources {
+		if v := value(res); v != "" {
+			set[v] = true
+		}
+	}
+	for _, child := range module.ChildModules {
+		collectFilterValues(child, value, set)
+	}
+}
+
+// nextValue returns the value after current, cycling back to no filter after the last one
+func nextValue(values []string, current string) string {
+	if current == "" {
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	for i, v := range values {
+		if v == current && i+1 < len(values) {
+			return values[i+1]
+		}
+	}
+	return ""
+}
+
+func resourceProvider(res cost.Resource) string { return res.Provider }
+func resourceType(res cost.Resource) string     { return res.Type }
+func resourceRegion(res cost.Resource) string   { return res.Region }
+
+// breadcrumb returns the path of the module from the root module
+func breadcrumb(path []string) string {
+	return strings.Join(append([]string{"root"}, path...), " › ")
+}
@@ -0,0 +1,154 @@
+package cost
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"strings"
+)
+
+// GroupsModel shows the costs of the whole state aggregated by one of the groupings,
+// each grouping is a tab next to the modules view
+type GroupsModel struct {
+	label        string
+	table        table.Model
+	opts         Options
+	index        int
+	modulesModel ResourcesModel
+}
+
+func (m GroupsModel) Init() tea.Cmd { return nil }
+
+func (m GroupsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "left", "esc":
+			return m.modulesModel, cmd
+		case "tab":
+			if m.index+1 >= len(m.opts.Groupings) {
+				return m.modulesModel, cmd
+			}
+			return mustGroupsModel(m.modulesModel, m.index+1), cmd
+		case "shift+tab":
+			if m.index == 0 {
+				return m.modulesModel, cmd
+			}
+			return mustGroupsModel(m.modulesModel, m.index-1), cmd
+		}
+	}
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m GroupsModel) View() string {
+	output := "Switch views by pressing [TAB] Navigate to modules by pressing ← Quit by pressing Q or [CTRL+C]\n\n"
+	output += tabBar(m.opts.Groupings, m.index+1) + "\n"
+	output += bold.Sprint(m.label) + "\n" + baseStyle.Render(m.table.View()) + "\n"
+	output += "To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md"
+	return output
+}
+
+// tabBar shows the modules view and the groupings as tabs, active is 0 for the modules view
+func tabBar(groupings []cost.Grouping, active int) string {
+	tabs := []string{"Modules"}
+	for _, g := range groupings {
+		tabs = append(tabs, groupingTitle(g))
+	}
+	for i, tab := range tabs {
+		if i == active {
+			tabs[i] = bold.Sprint("[" + tab + "]")
+		} else {
+			tabs[i] = faint.Sprint(" " + tab + " ")
+		}
+	}
+	return strings.Join(tabs, " ")
+}
+
+func groupingTitle(g cost.Grouping) string {
+	switch g.By {
+	case cost.GroupByType:
+		return "Type"
+	case cost.GroupByRegion:
+		return "Region"
+	case cost.GroupByProvider:
+		return "Provider"
+	case cost.GroupByService:
+		return "Service"
+	case cost.GroupByTag:
+		return "Tag: " + g.TagKey
+	}
+	return g.Name()
+}
+
+func mustGroupsModel(modulesModel ResourcesModel, index int) tea.Model {
+	model, err := getGroupsModel(modulesModel, index)
+	if err != nil {
+		panic(err)
+	}
+	return model
+}
+
+// getGroupsModel returns the view of the grouping at the index, the costs are grouped over the root module
+func getGroupsModel(modulesModel ResourcesModel, index int) (tea.Model, error) {
+	root := modulesModel
+	for root.parentModel != nil {
+		root = *root.parentModel
+	}
+	opts := modulesModel.opts
+	g := opts.Groupings[index]
+	groups, err := root.state.GroupCosts(g)
+	if err != nil {
+		return nil, err
+	}
+	label, err := totalLabel(root.state, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	longestName := len(groupingTitle(g))
+	for _, group := range groups {
+		if len(group.Name) > longestName {
+			longestName = len(group.Name)
+		}
+	}
+	columns := []table.Column{
+		{Title: groupingTitle(g), Width: longestName},
+		{Title: "Resources", Width: 10},
+		{Title: opts.period().Title(), Width: max(12, len(opts.period().Title()))},
+	}
+
+	ac := opts.accounting()
+	var rows []table.Row
+	for _, group := range groups {
+		rows = append(rows, table.Row{group.Name, fmt.Sprintf("%d", len(group.Resources)), ac.FormatMoney(group.CostForPeriod(opts.period()).Decimal)})
+	}
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(10),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("#808080")).
+		Bold(true).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderLeft(true).BorderBottom(false).BorderRight(false).BorderTop(false)
+	t.SetStyles(s)
+
+	m := GroupsModel{label, t, opts, index, modulesModel}
+	return m, nil
+}
@@ -0,0 +1,331 @@
+package cost
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/output"
+	"github.com/kaytu-io/pennywise/pkg/pricing"
+	"github.com/kaytu-io/pennywise/pkg/recommendation"
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+type htmlModule struct {
+	// Period is the title of the cost columns
+	Period         string
+	Name           string
+	Cost           string
+	ResourcesCount int
+	Resources      []htmlResource
+	ChildModules   []htmlModule
+}
+
+type htmlResource struct {
+	Name       string
+	Type       string
+	Region     string
+	Cost       string
+	SortCost   string
+	Components []htmlComponent
+	// Unpriced is set if the price of any of the components could not be found
+	Unpriced bool
+}
+
+type htmlComparison struct {
+	Current   string
+	Savings   string
+	Options   []string
+	Resources []htmlResourceComparison
+	Notes     []string
+}
+
+type htmlResourceComparison struct {
+	Address string
+	Type    string
+	Current string
+	// Costs are in the order of the options, "-" if the resource couldn't be priced with the option
+	// and "n/a" if its type has no pricing for the option
+	Costs       []string
+	Cheapest    string
+	Savings     string
+	SortSavings string
+}
+
+type htmlRecommendations struct {
+	Savings         string
+	Recommendations []htmlRecommendation
+}
+
+type htmlRecommendation struct {
+	Address     string
+	Description string
+	Attribute   string
+	Current     string
+	Suggested   string
+	Cost        string
+	Savings     string
+	SortSavings string
+}
+
+type htmlComponent struct {
+	Name            string
+	Rate            string
+	HourlyQuantity  string
+	MonthlyQuantity string
+	Unit            string
+	Cost            string
+	Error           string
+	Details         []string
+	// Upfront is the one-time upfront cost and its term, empty if there is none
+	Upfront string
+}
+
+var costTemplate = template.Must(template.New("cost").Parse(`{{define "module"}}<details open>
+<summary><b>{{.Name}}</b> {{.Cost}} <span class="muted">({{.ResourcesCount}} resources)</span></summary>
+{{if .Resources}}<table class="sortable">
+<thead><tr><th>Resource</th><th>Type</th><th>Region</th><th class="number">{{$.Period}}</th></tr></thead>
+<tbody>
+{{range .Resources}}<tr>
+<td data-sort="{{.Name}}">{{if .Components}}<details><summary>{{.Name}}{{if .Unpriced}} <span class="warning">⚠</span>{{end}}</summary>
+<table>
+<thead><tr><th>Component</th><th class="number">Unit Price</th><th class="number">Hourly Qty</th><th class="number">Monthly Qty</th><th>Unit</th><th class="number">{{$.Period}}</th></tr></thead>
+<tbody>
+{{range .Components}}<tr><td>{{.Name}}{{if .Upfront}} <span class="muted">({{.Upfront}})</span>{{end}}{{if .Error}} <span class="warning">⚠ {{.Error}}</span>{{end}}{{range .Details}}<div class="muted">{{.}}</div>{{end}}</td><td class="number">{{.Rate}}</td><td class="number">{{.HourlyQuantity}}</td><td class="number">{{.MonthlyQuantity}}</td><td>{{.Unit}}</td><td class="number">{{.Cost}}</td></tr>
+{{end}}</tbody>
+</table>
+</details>{{else}}{{.Name}}{{end}}</td>
+<td>{{.Type}}</td><td>{{.Region}}</td><td class="number" data-sort="{{.SortCost}}">{{.Cost}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+{{end}}{{range .ChildModules}}{{template "module" .}}{{end}}</details>
+{{end}}<p class="summary">Total {{.Period}}: <b>{{.Total}}</b> for {{.ResourcesCount}} resources</p>
+{{if .Upfront}}<p>Upfront cost (one-time): <b>{{.Upfront}}</b>, {{if .CashFlow}}paid at the start of each term{{else}}amortized over its term{{end}} in the costs</p>
+{{end}}{{if .Unpriced}}<p class="warning">⚠ {{.Unpriced}} components could not be priced and are not included in the costs</p>
+{{end}}<div class="charts">
+{{range .Charts}}{{.}}{{end}}</div>
+{{with .Comparison}}<h2>Pricing comparison</h2>
+<p>Potential savings: <b>{{.Savings}}</b> of {{.Current}}</p>
+<table class="sortable">
+<thead><tr><th>Resource</th><th>Type</th><th class="number">Current</th>{{range .Options}}<th class="number">{{.}}</th>{{end}}<th>Cheapest</th><th class="number">Savings</th></tr></thead>
+<tbody>
+{{range .Resources}}<tr><td>{{.Address}}</td><td>{{.Type}}</td><td class="number">{{.Current}}</td>{{range .Costs}}<td class="number">{{.}}</td>{{end}}<td>{{.Cheapest}}</td><td class="number" data-sort="{{.SortSavings}}">{{.Savings}}</td></tr>
+{{end}}</tbody>
+</table>
+{{range .Notes}}<p class="muted">n/a: {{.}}</p>
+{{end}}{{end}}{{with .Recommendations}}<h2>Recommendations</h2>
+<p>Estimated savings: <b>{{.Savings}}</b></p>
+{{if .Recommendations}}<table class="sortable">
+<thead><tr><th>Resource</th><th>Attribute</th><th>Change</th><th class="number">{{$.Period}}</th><th class="number">Savings</th></tr></thead>
+<tbody>
+{{range .Recommendations}}<tr><td>{{.Address}}<div class="muted">{{.Description}}</div></td><td>{{.Attribute}}</td><td>{{.Current}} → {{.Suggested}}</td><td class="number">{{.Cost}}</td><td class="number decrease" data-sort="{{.SortSavings}}">{{.Savings}}</td></tr>
+{{end}}</tbody>
+</table>
+{{else}}<p class="muted">No cheaper alternatives found</p>
+{{end}}{{end}}<h2>Modules</h2>
+{{template "module" .Root}}`))
+
+// HTMLString returns a self-contained html report of the costs for the period with the module tree, the component breakdown
+// of each resource and a chart for each of the groupings
+func HTMLString(s *cost.ModularState, opts Options) (string, error) {
+	ac := opts.accounting()
+	period := opts.period()
+	totalCost, err := s.CostForPeriod(period)
+	if err != nil {
+		return "", err
+	}
+	root, err := buildHTMLModule("root module", *s, period, ac)
+	if err != nil {
+		return "", err
+	}
+	upfrontCost, _, err := s.UpfrontCosts()
+	if err != nil {
+		return "", err
+	}
+	var upfront string
+	if !upfrontCost.IsZero() {
+		upfront = ac.FormatMoney(upfrontCost.Decimal)
+	}
+
+	var charts []template.HTML
+	for _, g := range opts.Groupings {
+		groups, err := s.GroupCosts(g)
+		if err != nil {
+			return "", err
+		}
+		var bars []output.ChartBar
+		for _, group := range groups {
+			bars = append(bars, output.ChartBar{Label: group.Name, Value: group.CostForPeriod(period).Decimal})
+		}
+		chart, err := output.BarChart(fmt.Sprintf("%s by %s", period.Title(), g.Name()), bars, func(d decimal.Decimal) string {
+			return ac.FormatMoney(d)
+		})
+		if err != nil {
+			return "", err
+		}
+		charts = append(charts, chart)
+	}
+
+	var body strings.Builder
+	err = costTemplate.Execute(&body, struct {
+		Period          string
+		Total           string
+		ResourcesCount  int
+		Upfront         string
+		CashFlow        bool
+		Unpriced        int
+		Charts          []template.HTML
+		Comparison      *htmlComparison
+		Recommendations *htmlRecommendations
+		Root            htmlModule
+	}{period.Title(), ac.FormatMoney(totalCost.Decimal), s.TotalResourcesCount(), upfront, period.CashFlow, len(s.UnpricedComponents()), charts,
+		buildHTMLComparison(opts.Comparison, ac), buildHTMLRecommendations(opts.Recommendations, ac), root})
+	if err != nil {
+		return "", fmt.Errorf("failed to render html report: %w", err)
+	}
+	return output.HTMLPage("Pennywise cost estimation", template.HTML(body.String()))
+}
+
+func buildHTMLComparison(c *pricing.Comparison, ac *accounting.Accounting) *htmlComparison {
+	if c == nil {
+		return nil
+	}
+	comparison := &htmlComparison{
+		Current: ac.FormatMoney(c.Current()),
+		Savings: ac.FormatMoney(c.Savings()),
+		Options: pricing.OptionNames,
+		Notes:   c.Notes(),
+	}
+	for _, r := range c.Resources {
+		resource := htmlResourceComparison{
+			Address:     r.Address,
+			Type:        r.Type,
+			Current:     ac.FormatMoney(r.Current),
+			Savings:     ac.FormatMoney(r.Savings()),
+			SortSavings: r.Savings().String(),
+			Cheapest:    "-",
+		}
+		for _, name := range pricing.OptionNames {
+			if optionCost, ok := r.Costs[name]; ok {
+				resource.Costs = append(resource.Costs, ac.FormatMoney(optionCost))
+			} else if !pricing.HasOption(r.Type, name) {
+				resource.Costs = append(resource.Costs, "n/a")
+			} else {
+				resource.Costs = append(resource.Costs, "-")
+			}
+		}
+		if cheapest, _, ok := r.Cheapest(); ok {
+			resource.Cheapest = cheapest
+		}
+		comparison.Resources = append(comparison.Resources, resource)
+	}
+	return comparison
+}
+
+func buildHTMLRecommendations(r *recommendation.Report, ac *accounting.Accounting) *htmlRecommendations {
+	if r == nil {
+		return nil
+	}
+	recommendations := &htmlRecommendations{Savings: ac.FormatMoney(r.Savings())}
+	for _, rec := range r.Recommendations {
+		recommendations.Recommendations = append(recommendations.Recommendations, htmlRecommendation{
+			Address:     rec.Address,
+			Description: rec.Description,
+			Attribute:   rec.Attribute,
+			Current:     rec.Current,
+			Suggested:   rec.Suggested,
+			Cost:        ac.FormatMoney(rec.Cost),
+			Savings:     ac.FormatMoney(rec.Savings),
+			SortSavings: rec.Savings.String(),
+		})
+	}
+	return recommendations
+}
+
+func buildHTMLModule(name string, s cost.ModularState, period cost.Period, ac *accounting.Accounting) (htmlModule, error) {
+	moduleCost, err := s.CostForPeriod(period)
+	if err != nil {
+		return htmlModule{}, err
+	}
+	module := htmlModule{
+		Period:         period.Title(),
+		Name:           name,
+		Cost:           ac.FormatMoney(moduleCost.Decimal),
+		ResourcesCount: s.TotalResourcesCount(),
+	}
+
+	resourceCosts := make(map[string]decimal.Decimal)
+	for resName, res := range s.Resources {
+		resourceCost, err := res.CostForPeriod(period)
+		if err != nil {
+			return htmlModule{}, err
+		}
+		resourceCosts[resName] = resourceCost.Decimal
+		resource := htmlResource{
+			Name:     resName,
+			Type:     res.Type,
+			Region:   res.Region,
+			Cost:     ac.FormatMoney(resourceCost.Decimal),
+			SortCost: resourceCost.Decimal.String(),
+		}
+		if !res.IsSupported {
+			resource.Cost = "not supported"
+			resource.SortCost = "-1"
+		}
+		var compNames []string
+		for compName := range res.Components {
+			compNames = append(compNames, compName)
+		}
+		sort.Strings(compNames)
+		for _, compName := range compNames {
+			for _, c := range res.Components[compName] {
+				rounded := c.GetRounded()
+				var componentError string
+				if !c.IsPriced() {
+					componentError = c.Error.Error()
+					resource.Unpriced = true
+				}
+				var upfront string
+				if upfrontCost := c.UpfrontCost(); !upfrontCost.IsZero() {
+					upfront = fmt.Sprintf("upfront %s %s", ac.FormatMoney(upfrontCost.Decimal), c.UpfrontTerm())
+				}
+				resource.Components = append(resource.Components, htmlComponent{
+					Name:            c.Name,
+					Rate:            rounded.Rate.Decimal.String(),
+					HourlyQuantity:  rounded.HourlyQuantity.String(),
+					MonthlyQuantity: rounded.MonthlyQuantity.String(),
+					Unit:            c.Unit,
+					Cost:            ac.FormatMoney(c.CostForPeriod(period).Decimal),
+					Error:           componentError,
+					Details:         c.Details,
+					Upfront:         upfront,
+				})
+			}
+		}
+		module.Resources = append(module.Resources, resource)
+	}
+	sort.Slice(module.Resources, func(i, j int) bool {
+		costI, costJ := resourceCosts[module.Resources[i].Name], resourceCosts[module.Resources[j].Name]
+		if !costI.Equal(costJ) {
+			return costI.GreaterThan(costJ)
+		}
+		return module.Resources[i].Name < module.Resources[j].Name
+	})
+
+	var childNames []string
+	for childName := range s.ChildModules {
+		childNames = append(childNames, childName)
+	}
+	sort.Strings(childNames)
+	for _, childName := range childNames {
+		child, err := buildHTMLModule(childName, s.ChildModules[childName], period, ac)
+		if err != nil {
+			return htmlModule{}, err
+		}
+		module.ChildModules = append(module.ChildModules, child)
+	}
+	return module, nil
+}
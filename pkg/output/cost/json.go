@@ -0,0 +1,290 @@
+package cost
+
+import (
+	"encoding/json"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/pricing"
+	"github.com/kaytu-io/pennywise/pkg/recommendation"
+	"github.com/shopspring/decimal"
+)
+
+type jsonState struct {
+	jsonModule
+	// Period is the period of the period_cost fields, they're left out for the monthly amortized costs
+	Period string `json:"period"`
+	// CostView is the view of the upfront costs in the period costs (amortized | cash-flow)
+	CostView string                 `json:"cost_view"`
+	Groups   map[string][]jsonGroup `json:"groups,omitempty"`
+	// UnpricedComponents is the number of components that their price could not be found
+	UnpricedComponents int `json:"unpriced_components"`
+	// PricingComparison are the costs of the compute resources with each purchase option for the period
+	PricingComparison *jsonComparison `json:"pricing_comparison,omitempty"`
+	// Recommendations are the cheaper alternatives of the resources with their savings for the period
+	Recommendations *jsonRecommendations `json:"recommendations,omitempty"`
+}
+
+type jsonRecommendations struct {
+	Savings         decimal.Decimal      `json:"savings"`
+	Recommendations []jsonRecommendation `json:"recommendations"`
+}
+
+type jsonRecommendation struct {
+	Rule        string          `json:"rule"`
+	Description string          `json:"description"`
+	Address     string          `json:"address"`
+	Attribute   string          `json:"attribute"`
+	Current     string          `json:"current"`
+	Suggested   string          `json:"suggested"`
+	Cost        decimal.Decimal `json:"cost"`
+	Savings     decimal.Decimal `json:"savings"`
+}
+
+type jsonComparison struct {
+	Current   decimal.Decimal          `json:"current"`
+	Savings   decimal.Decimal          `json:"savings"`
+	Resources []jsonResourceComparison `json:"resources"`
+	Notes     []string                 `json:"notes,omitempty"`
+}
+
+type jsonResourceComparison struct {
+	Address     string                     `json:"address"`
+	Type        string                     `json:"type"`
+	Current     decimal.Decimal            `json:"current"`
+	Costs       map[string]decimal.Decimal `json:"costs"`
+	Unavailable []string                   `json:"unavailable,omitempty"`
+	Cheapest    string                     `json:"cheapest,omitempty"`
+	Savings     decimal.Decimal            `json:"savings"`
+}
+
+type jsonGroup struct {
+	Name        string           `json:"name"`
+	MonthlyCost decimal.Decimal  `json:"monthly_cost"`
+	PeriodCost  *decimal.Decimal `json:"period_cost,omitempty"`
+	UpfrontCost *decimal.Decimal `json:"upfront_cost,omitempty"`
+	Resources   []string         `json:"resources"`
+}
+
+type jsonModule struct {
+	MonthlyCost  decimal.Decimal         `json:"monthly_cost"`
+	PeriodCost   *decimal.Decimal        `json:"period_cost,omitempty"`
+	UpfrontCost  *decimal.Decimal        `json:"upfront_cost,omitempty"`
+	Resources    map[string]jsonResource `json:"resources,omitempty"`
+	ChildModules map[string]jsonModule   `json:"child_modules,omitempty"`
+}
+
+type jsonResource struct {
+	Type        string            `json:"type"`
+	Provider    string            `json:"provider"`
+	Region      string            `json:"region,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	IsSupported bool              `json:"is_supported"`
+	MonthlyCost decimal.Decimal   `json:"monthly_cost"`
+	PeriodCost  *decimal.Decimal  `json:"period_cost,omitempty"`
+	UpfrontCost *decimal.Decimal  `json:"upfront_cost,omitempty"`
+	Components  []jsonComponent   `json:"components,omitempty"`
+}
+
+type jsonComponent struct {
+	Name            string           `json:"name"`
+	Unit            string           `json:"unit"`
+	Rate            decimal.Decimal  `json:"rate"`
+	HourlyQuantity  decimal.Decimal  `json:"hourly_quantity"`
+	MonthlyQuantity decimal.Decimal  `json:"monthly_quantity"`
+	MonthlyCost     decimal.Decimal  `json:"monthly_cost"`
+	PeriodCost      *decimal.Decimal `json:"period_cost,omitempty"`
+	UpfrontQuantity *decimal.Decimal `json:"upfront_quantity,omitempty"`
+	UpfrontMonths   int              `json:"upfront_months,omitempty"`
+	UpfrontCost     *decimal.Decimal `json:"upfront_cost,omitempty"`
+	// UpfrontEstimated is set if the upfront payment is estimated from the payment option instead of being priced
+	UpfrontEstimated bool     `json:"upfront_estimated,omitempty"`
+	Error            string   `json:"error,omitempty"`
+	Details          []string `json:"details,omitempty"`
+}
+
+// JSONString returns the costs of the modules, resources and components as json,
+// along with the costs aggregated by each of the groupings. The costs for the period are added next to the monthly costs
+// and the one-time upfront costs are added if there are any.
+func JSONString(s *cost.ModularState, opts Options) (string, error) {
+	period := opts.period()
+	module, err := buildJSONModule(*s, period)
+	if err != nil {
+		return "", err
+	}
+	state := jsonState{
+		jsonModule: module,
+		Period:     period.Name,
+		CostView:   period.View(),
+		Groups:     make(map[string][]jsonGroup),
+
+		UnpricedComponents: len(s.UnpricedComponents()),
+	}
+	for _, g := range opts.Groupings {
+		groups, err := s.GroupCosts(g)
+		if err != nil {
+			return "", err
+		}
+		for _, group := range groups {
+			state.Groups[g.Name()] = append(state.Groups[g.Name()], jsonGroup{
+				Name:        group.Name,
+				MonthlyCost: group.Cost.Decimal,
+				PeriodCost:  periodCost(period, group.CostForPeriod(period)),
+				UpfrontCost: upfrontCost(group.Upfront),
+				Resources:   group.Resources,
+			})
+		}
+	}
+	if opts.Comparison != nil {
+		state.PricingComparison = buildJSONComparison(*opts.Comparison)
+	}
+	if opts.Recommendations != nil {
+		state.Recommendations = buildJSONRecommendations(*opts.Recommendations)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func buildJSONModule(s cost.ModularState, period cost.Period) (jsonModule, error) {
+	moduleCost, err := s.Cost()
+	if err != nil {
+		return jsonModule{}, err
+	}
+	modulePeriodCost, err := s.CostForPeriod(period)
+	if err != nil {
+		return jsonModule{}, err
+	}
+	moduleUpfront, _, err := s.UpfrontCosts()
+	if err != nil {
+		return jsonModule{}, err
+	}
+	module := jsonModule{
+		MonthlyCost:  moduleCost.Decimal,
+		PeriodCost:   periodCost(period, modulePeriodCost),
+		UpfrontCost:  upfrontCost(moduleUpfront),
+		Resources:    make(map[string]jsonResource),
+		ChildModules: make(map[string]jsonModule),
+	}
+	for name, res := range s.Resources {
+		resourceCost, err := res.Cost()
+		if err != nil {
+			return jsonModule{}, err
+		}
+		resourcePeriodCost, err := res.CostForPeriod(period)
+		if err != nil {
+			return jsonModule{}, err
+		}
+		resourceUpfront, _, err := res.UpfrontCosts()
+		if err != nil {
+			return jsonModule{}, err
+		}
+		resource := jsonResource{
+			Type:        res.Type,
+			Provider:    res.Provider,
+			Region:      res.Region,
+			Tags:        res.Tags,
+			IsSupported: res.IsSupported,
+			MonthlyCost: resourceCost.Decimal,
+			PeriodCost:  periodCost(period, resourcePeriodCost),
+			UpfrontCost: upfrontCost(resourceUpfront),
+		}
+		for _, comps := range res.Components {
+			for _, c := range comps {
+				var componentError string
+				if !c.IsPriced() {
+					componentError = c.Error.Error()
+				}
+				var upfrontQuantity *decimal.Decimal
+				var upfrontMonths int
+				if !c.UpfrontQuantity.IsZero() {
+					quantity := c.UpfrontQuantity
+					upfrontQuantity = &quantity
+					upfrontMonths = c.Months()
+				}
+				resource.Components = append(resource.Components, jsonComponent{
+					Name:             c.Name,
+					Unit:             c.Unit,
+					Rate:             c.Rate.Decimal,
+					HourlyQuantity:   c.HourlyQuantity,
+					MonthlyQuantity:  c.MonthlyQuantity,
+					MonthlyCost:      c.Cost().Decimal,
+					PeriodCost:       periodCost(period, c.CostForPeriod(period)),
+					UpfrontQuantity:  upfrontQuantity,
+					UpfrontMonths:    upfrontMonths,
+					UpfrontCost:      upfrontCost(c.UpfrontCost()),
+					UpfrontEstimated: c.UpfrontEstimated,
+					Error:            componentError,
+					Details:          c.Details,
+				})
+			}
+		}
+		module.Resources[name] = resource
+	}
+	for name, child := range s.ChildModules {
+		childModule, err := buildJSONModule(child, period)
+		if err != nil {
+			return jsonModule{}, err
+		}
+		module.ChildModules[name] = childModule
+	}
+	return module, nil
+}
+
+func buildJSONComparison(c pricing.Comparison) *jsonComparison {
+	comparison := &jsonComparison{
+		Current:   c.Current(),
+		Savings:   c.Savings(),
+		Resources: []jsonResourceComparison{},
+		Notes:     c.Notes(),
+	}
+	for _, r := range c.Resources {
+		cheapest, _, _ := r.Cheapest()
+		comparison.Resources = append(comparison.Resources, jsonResourceComparison{
+			Address:     r.Address,
+			Type:        r.Type,
+			Current:     r.Current,
+			Costs:       r.Costs,
+			Unavailable: r.Unavailable(),
+			Cheapest:    cheapest,
+			Savings:     r.Savings(),
+		})
+	}
+	return comparison
+}
+
+func buildJSONRecommendations(r recommendation.Report) *jsonRecommendations {
+	recommendations := &jsonRecommendations{
+		Savings:         r.Savings(),
+		Recommendations: []jsonRecommendation{},
+	}
+	for _, rec := range r.Recommendations {
+		recommendations.Recommendations = append(recommendations.Recommendations, jsonRecommendation{
+			Rule:        rec.Rule,
+			Description: rec.Description,
+			Address:     rec.Address,
+			Attribute:   rec.Attribute,
+			Current:     rec.Current,
+			Suggested:   rec.Suggested,
+			Cost:        rec.Cost,
+			Savings:     rec.Savings,
+		})
+	}
+	return recommendations
+}
+
+// periodCost returns the cost for the period, or nil for the monthly amortized costs since it's the same as the monthly cost
+func periodCost(period cost.Period, c cost.Cost) *decimal.Decimal {
+	if period.IsMonthly() {
+		return nil
+	}
+	return &c.Decimal
+}
+
+// upfrontCost returns the one-time upfront cost, or nil if there is none
+func upfrontCost(c cost.Cost) *decimal.Decimal {
+	if c.IsZero() {
+		return nil
+	}
+	return &c.Decimal
+}
@@ -3,11 +3,13 @@ package cost
 import (
 	"fmt"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kaytu-io/pennywise/pkg/cost"
-	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
 	"golang.org/x/crypto/ssh/terminal"
+	"sort"
 )
 
 var baseStyle = lipgloss.NewStyle().
@@ -22,6 +24,24 @@ type ResourcesModel struct {
 	freeResources        []string
 	unsupportedResources map[string][]string
 	longestName          int
+	path                 []string
+	rows                 []resourceRow
+	search               textinput.Model
+	searching            bool
+	filter               resourcesFilter
+	sortMode             sortMode
+	opts                 Options
+	// unpricedComponents is the number of components in the module that their price could not be found
+	unpricedComponents int
+}
+
+// resourceRow is a row of the resources table before it's filtered and sorted
+type resourceRow struct {
+	name           string
+	isModule       bool
+	isSummary      bool
+	resourcesCount int
+	cost           decimal.Decimal
 }
 
 func (m ResourcesModel) Init() tea.Cmd { return nil }
@@ -30,6 +50,28 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.searching {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "enter":
+				m.searching = false
+				m.search.Blur()
+				return m, cmd
+			case "esc":
+				m.searching = false
+				m.search.Blur()
+				m.search.Reset()
+				m.filter.query = ""
+				m.refreshTable()
+				return m, cmd
+			}
+			m.search, cmd = m.search.Update(msg)
+			m.filter.query = m.search.Value()
+			m.refreshTable()
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -37,8 +79,44 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.parentModel != nil {
 				return *m.parentModel, cmd
 			}
+		case "tab":
+			if len(m.opts.Groupings) > 0 {
+				return mustGroupsModel(m, 0), cmd
+			}
+		case "shift+tab":
+			if len(m.opts.Groupings) > 0 {
+				return mustGroupsModel(m, len(m.opts.Groupings)-1), cmd
+			}
+		case "/":
+			m.searching = true
+			return m, m.search.Focus()
+		case "esc":
+			m.filter = resourcesFilter{}
+			m.search.Reset()
+			m.refreshTable()
+			return m, cmd
+		case "s":
+			m.sortMode = m.sortMode.next()
+			m.refreshTable()
+			return m, cmd
+		case "p":
+			m.filter.provider = nextValue(filterValues(*m.state, resourceProvider), m.filter.provider)
+			m.refreshTable()
+			return m, cmd
+		case "t":
+			m.filter.resourceType = nextValue(filterValues(*m.state, resourceType), m.filter.resourceType)
+			m.refreshTable()
+			return m, cmd
+		case "r":
+			m.filter.region = nextValue(filterValues(*m.state, resourceRegion), m.filter.region)
+			m.refreshTable()
+			return m, cmd
 		case "right", "enter":
-			name := m.table.SelectedRow()[0]
+			row := m.table.SelectedRow()
+			if row == nil {
+				return m, cmd
+			}
+			name := row[0]
 			if name == "Free Resources" {
 				freeResourcesModel, err := getFreeResourcesModel(m)
 				if err != nil {
@@ -53,14 +131,14 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return unsupportedModel, cmd
 			}
 			if resource, ok := m.state.Resources[name]; ok {
-				compsModel, err := getComponentsModel(name, m.table.SelectedRow()[2], resource.Components, m)
+				compsModel, err := getComponentsModel(name, row[2], resource, m)
 				if err != nil {
 					panic(err)
 				}
 				return compsModel, cmd
 			} else {
 				module := m.state.ChildModules[name]
-				moduleCost, err := module.Cost()
+				moduleCost, err := module.CostForPeriod(m.opts.period())
 				if err != nil {
 					panic(err)
 				}
@@ -75,9 +153,9 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						longestName = len(n)
 					}
 				}
-				ac := accounting.Accounting{Symbol: "$", Precision: 2}
-				label := fmt.Sprintf("Module total Cost: %s", ac.FormatMoney(moduleCost.Decimal))
-				resModel, err := getResourcesModel(label, &module, longestName, &m)
+				label := fmt.Sprintf("Module total %s: %s", m.opts.period().Title(), m.opts.accounting().FormatMoney(moduleCost.Decimal))
+				path := append(append([]string{}, m.path...), name)
+				resModel, err := getResourcesModel(label, &module, longestName, &m, path, m.opts)
 				if err != nil {
 					panic(err)
 				}
@@ -90,36 +168,113 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m ResourcesModel) View() string {
-	output := "Navigate to details by pressing → or [ENTER] Quit by pressing Q or [CTRL+C]\n\n"
-	output += bold.Sprint(m.label) + "\n" + baseStyle.Render(m.table.View()) + "\n"
+	output := "Navigate to details by pressing → or [ENTER] Switch views by pressing [TAB] Quit by pressing Q or [CTRL+C]\n"
+	output += "Search by pressing /, sort by pressing S, filter by provider, type or region by pressing P, T or R, clear filters by pressing [ESC]\n\n"
+	if len(m.opts.Groupings) > 0 {
+		output += tabBar(m.opts.Groupings, 0) + "\n"
+	}
+	output += faint.Sprint(breadcrumb(m.path)) + "\n"
+	output += bold.Sprint(m.label) + "\n"
+	if m.searching || m.filter.query != "" {
+		output += m.search.View() + "\n"
+	}
+	output += baseStyle.Render(m.table.View()) + "\n"
+	output += m.statusLine() + "\n"
+	if m.unpricedComponents > 0 {
+		output += fmt.Sprintf("%s %d components could not be priced and are not included in the costs, see the resource details\n", cost.UnpricedMark, m.unpricedComponents)
+	}
 	output += "To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md"
 	return output
 }
 
-func getResourcesModel(label string, state *cost.ModularState, longestName int, parentModel *ResourcesModel) (tea.Model, error) {
+// statusLine shows the sort order and the selected filters
+func (m ResourcesModel) statusLine() string {
+	valueOrAll := func(v string) string {
+		if v == "" {
+			return "all"
+		}
+		return v
+	}
+	return fmt.Sprintf("Sorted by %s | Provider: %s | Type: %s | Region: %s", m.sortMode,
+		valueOrAll(m.filter.provider), valueOrAll(m.filter.resourceType), valueOrAll(m.filter.region))
+}
+
+// refreshTable sets the table rows matching the filter in the selected order.
+// Free and unsupported resources are hidden while filtering.
+func (m *ResourcesModel) refreshTable() {
+	var rows []resourceRow
+	for _, row := range m.rows {
+		switch {
+		case row.isSummary:
+			if m.filter.isActive() {
+				continue
+			}
+		case row.isModule:
+			if !m.filter.matchModule(row.name, m.state.ChildModules[row.name]) {
+				continue
+			}
+		default:
+			if !m.filter.matchResource(row.name, m.state.Resources[row.name]) {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		switch m.sortMode {
+		case sortByName:
+			return rows[i].name < rows[j].name
+		case sortByResourcesCount:
+			if rows[i].resourcesCount != rows[j].resourcesCount {
+				return rows[i].resourcesCount > rows[j].resourcesCount
+			}
+		default:
+			if !rows[i].cost.Equal(rows[j].cost) {
+				return rows[i].cost.GreaterThan(rows[j].cost)
+			}
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	ac := m.opts.accounting()
+	var tableRows []table.Row
+	for _, row := range rows {
+		var count string
+		if row.isModule || row.isSummary {
+			count = fmt.Sprintf("%d", row.resourcesCount)
+		}
+		tableRows = append(tableRows, table.Row{row.name, count, ac.FormatMoney(row.cost), "→"})
+	}
+	m.table.SetRows(tableRows)
+	m.table.SetCursor(0)
+}
+
+func getResourcesModel(label string, state *cost.ModularState, longestName int, parentModel *ResourcesModel, path []string, opts Options) (tea.Model, error) {
 	w, _, err := terminal.GetSize(0)
 	if err != nil {
 		return nil, err
 	}
 	if (longestName + 33) > w {
-		return getSmallTerminalModelModel(label, state, w-36, parentModel)
+		return getSmallTerminalModelModel(label, state, w-36, parentModel, path, opts)
 	}
 	columns := []table.Column{
 		{Title: "Name", Width: longestName},
 		{Title: "Resources", Width: 10},
-		{Title: "Monthly Cost", Width: 12},
+		{Title: opts.period().Title(), Width: max(12, len(opts.period().Title()))},
+		{Title: "", Width: 1},
 	}
 
-	var rows []table.Row
+	var rows []resourceRow
 	var freeResources []string
 	unsupportedServices := make(map[string][]string)
 
 	for name, module := range state.ChildModules {
-		cost, err := module.Cost()
+		cost, err := module.CostForPeriod(opts.period())
 		if err != nil {
 			return nil, err
 		}
-		rows = append(rows, []string{name, fmt.Sprintf("%d", module.TotalResourcesCount()), cost.Decimal.String()})
+		rows = append(rows, resourceRow{name: name, isModule: true, resourcesCount: module.TotalResourcesCount(), cost: cost.Decimal})
 	}
 
 	for name, resource := range state.Resources {
@@ -130,7 +285,7 @@ func getResourcesModel(label string, state *cost.ModularState, longestName int,
 			unsupportedServices[resource.Type] = append(unsupportedServices[resource.Type], name)
 			continue
 		}
-		cost, err := resource.Cost()
+		cost, err := resource.CostForPeriod(opts.period())
 		if err != nil {
 			return nil, err
 		}
@@ -138,23 +293,16 @@ func getResourcesModel(label string, state *cost.ModularState, longestName int,
 			freeResources = append(freeResources, name)
 			continue
 		}
-		rows = append(rows, []string{name, "", cost.Decimal.String()})
+		rows = append(rows, resourceRow{name: name, resourcesCount: 1, cost: cost.Decimal})
 	}
 	if len(freeResources) > 0 {
-		rows = append(rows, []string{"Free Resources", fmt.Sprintf("%d", len(freeResources)), "0"})
+		rows = append(rows, resourceRow{name: "Free Resources", isSummary: true, resourcesCount: len(freeResources)})
 	}
 	if len(unsupportedServices) > 0 {
-		rows = append(rows, []string{"Unsupported", fmt.Sprintf("%d", len(unsupportedServices)), "0"})
-	}
-	rows = sortRows(rows)
-	rows = makeNumbersAccounting(rows)
-	columns = append(columns, table.Column{Title: "", Width: 1})
-	for i, _ := range rows {
-		rows[i] = append(rows[i], "→")
+		rows = append(rows, resourceRow{name: "Unsupported", isSummary: true, resourcesCount: len(unsupportedServices)})
 	}
 	t := table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(10),
 	)
@@ -173,6 +321,24 @@ func getResourcesModel(label string, state *cost.ModularState, longestName int,
 		BorderLeft(true).BorderBottom(false).BorderRight(false).BorderTop(false)
 	t.SetStyles(s)
 
-	m := ResourcesModel{label, t, state, parentModel, freeResources, unsupportedServices, longestName}
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search resources and modules"
+
+	m := ResourcesModel{
+		label:                label,
+		table:                t,
+		state:                state,
+		parentModel:          parentModel,
+		freeResources:        freeResources,
+		unsupportedResources: unsupportedServices,
+		longestName:          longestName,
+		path:                 path,
+		rows:                 rows,
+		search:               search,
+		opts:                 opts,
+		unpricedComponents:   len(state.UnpricedComponents()),
+	}
+	m.refreshTable()
 	return m, nil
 }
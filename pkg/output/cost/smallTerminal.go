@@ -10,6 +10,8 @@ type SmallTerminalModel struct {
 	parentModel *ResourcesModel
 	label       string
 	wSize       int
+	path        []string
+	opts        Options
 }
 
 func (m SmallTerminalModel) Init() tea.Cmd { return nil }
@@ -22,7 +24,7 @@ func (m SmallTerminalModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc", "q", "ctrl+c":
 			return m, tea.Quit
 		case "enter":
-			model, err := getResourcesModel(m.label, m.state, m.wSize, m.parentModel)
+			model, err := getResourcesModel(m.label, m.state, m.wSize, m.parentModel, m.path, m.opts)
 			if err != nil {
 				panic(err)
 			}
@@ -37,8 +39,8 @@ func (m SmallTerminalModel) View() string {
 		"Exit by pressing [ESC], q or [CTRL+C]"
 }
 
-func getSmallTerminalModelModel(label string, state *cost.ModularState, wSize int, parentModel *ResourcesModel) (tea.Model, error) {
+func getSmallTerminalModelModel(label string, state *cost.ModularState, wSize int, parentModel *ResourcesModel, path []string, opts Options) (tea.Model, error) {
 
-	m := SmallTerminalModel{state, parentModel, label, wSize}
+	m := SmallTerminalModel{state, parentModel, label, wSize, path, opts}
 	return m, nil
 }
@@ -4,12 +4,12 @@ import (
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kaytu-io/pennywise/pkg/cost"
-	"github.com/leekchan/accounting"
 	"os"
 )
 
-func ShowStateCosts(s *cost.ModularState) error {
-	totalCost, err := s.Cost()
+// ShowStateCosts shows the interactive view of the costs for the period, the groupings are shown as tabs next to the modules view
+func ShowStateCosts(s *cost.ModularState, opts Options) error {
+	label, err := totalLabel(s, opts)
 	if err != nil {
 		return err
 	}
@@ -24,9 +24,7 @@ func ShowStateCosts(s *cost.ModularState) error {
 			longestName = len(name)
 		}
 	}
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
-	label := fmt.Sprintf("Total Cost: %s", ac.FormatMoney(totalCost.Decimal))
-	model, err := getResourcesModel(label, s, longestName, nil)
+	model, err := getResourcesModel(label, s, longestName, nil, nil, opts)
 	if err != nil {
 		return err
 	}
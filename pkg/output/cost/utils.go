@@ -1,14 +1,66 @@
 package cost
 
 import (
+	"fmt"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/fatih/color"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/pricing"
+	"github.com/kaytu-io/pennywise/pkg/recommendation"
 	"github.com/leekchan/accounting"
 	"sort"
 	"strconv"
 )
 
+// Options defines how the costs are shown
+type Options struct {
+	// Groupings are the aggregations of the costs shown next to the modules
+	Groupings []cost.Grouping
+	// Period is the time horizon of the costs
+	Period cost.Period
+	// Currency of the costs, cost.DefaultCurrency if it's empty
+	Currency string
+	// Comparison is the comparison of the purchase options of the compute resources, nil if it's not requested
+	Comparison *pricing.Comparison
+	// Recommendations are the cheaper alternatives of the resources, nil if they're not requested
+	Recommendations *recommendation.Report
+}
+
+// period returns the period of the costs, monthly if it's not set
+func (o Options) period() cost.Period {
+	if o.Period.Name == "" {
+		period := cost.Monthly
+		period.CashFlow = o.Period.CashFlow
+		return period
+	}
+	return o.Period
+}
+
+// totalLabel returns the label of the total cost for the period of the module,
+// along with its one-time upfront cost if there is any
+func totalLabel(s *cost.ModularState, opts Options) (string, error) {
+	totalCost, err := s.CostForPeriod(opts.period())
+	if err != nil {
+		return "", err
+	}
+	label := fmt.Sprintf("Total %s: %s", opts.period().Title(), opts.accounting().FormatMoney(totalCost.Decimal))
+	upfront, _, err := s.UpfrontCosts()
+	if err != nil {
+		return "", err
+	}
+	if !upfront.IsZero() {
+		label += fmt.Sprintf(", Upfront Cost (one-time): %s", opts.accounting().FormatMoney(upfront.Decimal))
+	}
+	return label, nil
+}
+
+// accounting returns the formatter of the costs of the period in the currency
+func (o Options) accounting() *accounting.Accounting {
+	return cost.MoneyFormatter(o.Currency, o.period().Precision())
+}
+
 var bold = color.New(color.Bold)
+var faint = color.New(color.Faint)
 
 func sortRows(rows []table.Row) []table.Row {
 	sort.Slice(rows, func(i, j int) bool {
@@ -20,8 +72,7 @@ func sortRows(rows []table.Row) []table.Row {
 	return rows
 }
 
-func makeNumbersAccounting(rows []table.Row) []table.Row {
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
+func makeNumbersAccounting(rows []table.Row, ac *accounting.Accounting) []table.Row {
 	for _, row := range rows {
 		costFloat, _ := strconv.ParseFloat(row[len(row)-1], 64)
 		row[len(row)-1] = ac.FormatMoney(costFloat)
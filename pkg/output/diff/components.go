@@ -5,8 +5,8 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/kaytu-io/pennywise/pkg/schema"
-	"github.com/leekchan/accounting"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -14,6 +14,10 @@ type ComponentsModel struct {
 	label          string
 	table          table.Model
 	resourcesModel ResourcesModel
+	// pricingErrors are the pricing errors of the components in the same order as the table rows
+	pricingErrors []error
+	// notes are the notes about the planned change of the resource
+	notes []string
 }
 
 func (m ComponentsModel) Init() tea.Cmd { return nil }
@@ -36,11 +40,18 @@ func (m ComponentsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m ComponentsModel) View() string {
 	output := "Navigate to resources by pressing ← Quit by pressing Q or [CTRL+C]\n\n"
 	output += bold.Sprint(m.label) + "\n" + baseStyle.Render(m.table.View()) + "\n"
+	for _, note := range m.notes {
+		output += faint.Sprint(note) + "\n"
+	}
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.pricingErrors) && m.pricingErrors[cursor] != nil {
+		output += fmt.Sprintf("%s %v, it's not included in the costs\n", cost.UnpricedMark, m.pricingErrors[cursor])
+	}
 	output += "To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md"
 	return output
 }
 
-func getComponentsModel(resourceName, resourceCost string, components map[string][]schema.ComponentDiff, resModel ResourcesModel) (tea.Model, error) {
+func getComponentsModel(resourceName, resourceCost string, resource schema.ResourceDiff, resModel ResourcesModel) (tea.Model, error) {
+	components := resource.ComponentDiffs
 	var longestName int
 	for _, comps := range components {
 		for _, c := range comps {
@@ -66,7 +77,8 @@ func getComponentsModel(resourceName, resourceCost string, components map[string
 	}
 
 	var rows []table.Row
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
+	var pricingErrors []error
+	ac := cost.MoneyFormatter(resModel.state.Currency, 2)
 
 	for _, comps := range components {
 		for _, c := range comps {
@@ -111,9 +123,14 @@ func getComponentsModel(resourceName, resourceCost string, components map[string
 				monthlyCost = c.Component.MonthlyQuantity.String()
 				costDiff = "-" + ac.FormatMoney(c.CostDiff)
 			}
+			pricingError := c.PricingError()
+			if pricingError != nil {
+				componentName += " " + cost.UnpricedMark
+			}
 			row = append(row, componentName, rateString, hourlyCost,
 				monthlyCost, c.Component.Unit, costDiff)
 			rows = append(rows, row)
+			pricingErrors = append(pricingErrors, pricingError)
 		}
 	}
 
@@ -137,6 +154,7 @@ func getComponentsModel(resourceName, resourceCost string, components map[string
 		BorderForeground(lipgloss.Color("240")).
 		BorderLeft(true).BorderBottom(false).BorderRight(false).BorderTop(false)
 	t.SetStyles(s)
-	m := ComponentsModel{fmt.Sprintf("%s, Resource Total Cost: %s", resourceName, resourceCost), t, resModel}
+	m := ComponentsModel{fmt.Sprintf("%s, Resource Total Cost: %s", resourceName, resourceCost), t, resModel, pricingErrors,
+		resource.PlannedChangeNotes(5)}
 	return m, nil
 }
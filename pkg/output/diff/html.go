@@ -0,0 +1,205 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/output"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+type htmlModule struct {
+	Icon      string
+	Name      string
+	PriorCost string
+	NewCost   string
+	Delta     htmlDelta
+	Resources []htmlResource
+}
+
+type htmlResource struct {
+	Icon       string
+	Address    string
+	PriorCost  string
+	NewCost    string
+	Delta      htmlDelta
+	Components []htmlComponent
+	// Notes are the notes about the planned change of the resource
+	Notes []string
+}
+
+type htmlComponent struct {
+	Icon      string
+	Name      string
+	Unit      string
+	PriorCost string
+	NewCost   string
+	Delta     htmlDelta
+	Error     string
+}
+
+// htmlUpfront is the change of the one-time upfront costs
+type htmlUpfront struct {
+	// Estimated is set if an upfront payment is estimated from the payment option
+	Estimated bool
+	PriorCost string
+	NewCost   string
+	Delta     htmlDelta
+}
+
+// htmlDelta is a formatted cost change with its class and the value to sort by
+type htmlDelta struct {
+	Text  string
+	Class string
+	Sort  string
+}
+
+var diffTemplate = template.Must(template.New("diff").Parse(`<p class="summary">{{.Summary}}</p>
+<table>
+<thead><tr><th></th><th class="number">Before</th><th class="number">After</th><th class="number">Delta</th></tr></thead>
+<tbody><tr><td>Monthly cost</td><td class="number">{{.PriorCost}}</td><td class="number">{{.NewCost}}</td><td class="number {{.Delta.Class}}">{{.Delta.Text}}</td></tr>
+{{with .Upfront}}<tr><td>Upfront cost (one-time{{if .Estimated}}, estimated{{end}})</td><td class="number">{{.PriorCost}}</td><td class="number">{{.NewCost}}</td><td class="number {{.Delta.Class}}">{{.Delta.Text}}</td></tr>
+{{end}}</tbody>
+</table>
+{{with .Replacements}}<p class="warning">{{.}}</p>
+{{end}}{{if .Unpriced}}<p class="warning">⚠ {{.Unpriced}} components could not be priced and are not included in the costs</p>
+{{end}}<h2>Modules</h2>
+{{range .Modules}}<details open>
+<summary>{{.Icon}} <b>{{.Name}}</b> {{.PriorCost}} → {{.NewCost}} <span class="{{.Delta.Class}}">({{.Delta.Text}})</span></summary>
+<table class="sortable">
+<thead><tr><th></th><th>Resource</th><th class="number">Before</th><th class="number">After</th><th class="number">Delta</th></tr></thead>
+<tbody>
+{{range .Resources}}<tr>
+<td>{{.Icon}}</td>
+<td data-sort="{{.Address}}">{{if .Components}}<details><summary>{{.Address}}</summary>
+<table>
+<thead><tr><th></th><th>Component</th><th>Unit</th><th class="number">Before</th><th class="number">After</th><th class="number">Delta</th></tr></thead>
+<tbody>
+{{range .Components}}<tr><td>{{.Icon}}</td><td>{{.Name}}{{if .Error}} <span class="warning">⚠ {{.Error}}</span>{{end}}</td><td>{{.Unit}}</td><td class="number">{{.PriorCost}}</td><td class="number">{{.NewCost}}</td><td class="number {{.Delta.Class}}">{{.Delta.Text}}</td></tr>
+{{end}}</tbody>
+</table>
+</details>{{else}}{{.Address}}{{end}}{{range .Notes}}<br><small>{{.}}</small>{{end}}</td>
+<td class="number">{{.PriorCost}}</td><td class="number">{{.NewCost}}</td><td class="number {{.Delta.Class}}" data-sort="{{.Delta.Sort}}">{{.Delta.Text}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</details>
+{{end}}`))
+
+// HTMLString returns a self-contained html report of the diff with the costs before and after the change
+// of each module, resource and component
+func HTMLString(s *schema.ModularStateDiff) (string, error) {
+	ac := cost.MoneyFormatter(s.Currency, 2)
+
+	var modules []htmlModule
+	for _, mod := range flattenModules("", *s) {
+		if len(mod.resources) == 0 {
+			continue
+		}
+		module := htmlModule{
+			Icon:      actionIcons[mod.diff.Action],
+			Name:      mod.path,
+			PriorCost: ac.FormatMoney(mod.diff.PriorCost),
+			NewCost:   ac.FormatMoney(mod.diff.NewCost),
+			Delta:     newHTMLDelta(ac, mod.diff.NewCost.Sub(mod.diff.PriorCost)),
+		}
+		sort.SliceStable(mod.resources, func(i, j int) bool {
+			return resourceDelta(mod.resources[i].diff).Abs().GreaterThan(resourceDelta(mod.resources[j].diff).Abs())
+		})
+		for _, res := range mod.resources {
+			module.Resources = append(module.Resources, htmlResource{
+				Icon:       actionIcons[res.diff.Action],
+				Address:    res.address,
+				PriorCost:  ac.FormatMoney(res.diff.PriorCost),
+				NewCost:    ac.FormatMoney(res.diff.NewCost),
+				Delta:      newHTMLDelta(ac, resourceDelta(res.diff)),
+				Components: htmlComponents(res.diff, ac),
+				Notes:      res.diff.PlannedChangeNotes(0),
+			})
+		}
+		modules = append(modules, module)
+	}
+
+	var body strings.Builder
+	err := diffTemplate.Execute(&body, struct {
+		Summary   string
+		PriorCost string
+		NewCost   string
+		Delta     htmlDelta
+		Upfront   *htmlUpfront
+		// Replacements is the cost billed during the create before destroy replacements, empty if there are none
+		Replacements string
+		Unpriced     int
+		Modules      []htmlModule
+	}{
+		Summary:      strings.ReplaceAll(summaryLine(s, ac), "**", ""),
+		PriorCost:    ac.FormatMoney(s.PriorCost),
+		NewCost:      ac.FormatMoney(s.NewCost),
+		Delta:        newHTMLDelta(ac, s.NewCost.Sub(s.PriorCost)),
+		Upfront:      newHTMLUpfront(s, ac),
+		Replacements: replacementsLine(s, ac),
+		Unpriced:     len(s.UnpricedComponents()),
+		Modules:      modules,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render html report: %w", err)
+	}
+	return output.HTMLPage("Pennywise cost diff", template.HTML(body.String()))
+}
+
+func htmlComponents(res schema.ResourceDiff, ac *accounting.Accounting) []htmlComponent {
+	var names []string
+	for name := range res.ComponentDiffs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var components []htmlComponent
+	for _, name := range names {
+		for _, c := range res.ComponentDiffs[name] {
+			prior, current := c.Costs()
+			var componentError string
+			if err := c.PricingError(); err != nil {
+				componentError = err.Error()
+			}
+			components = append(components, htmlComponent{
+				Icon:      actionIcons[c.Action],
+				Name:      c.Component.Name,
+				Unit:      c.Component.Unit,
+				PriorCost: ac.FormatMoney(prior),
+				NewCost:   ac.FormatMoney(current),
+				Delta:     newHTMLDelta(ac, current.Sub(prior)),
+				Error:     componentError,
+			})
+		}
+	}
+	return components
+}
+
+// newHTMLUpfront returns the change of the upfront costs, nil if there are no upfront costs
+func newHTMLUpfront(s *schema.ModularStateDiff, ac *accounting.Accounting) *htmlUpfront {
+	prior, current := s.UpfrontCosts()
+	if prior.IsZero() && current.IsZero() {
+		return nil
+	}
+	return &htmlUpfront{
+		Estimated: s.UpfrontEstimated(),
+		PriorCost: ac.FormatMoney(prior),
+		NewCost:   ac.FormatMoney(current),
+		Delta:     newHTMLDelta(ac, current.Sub(prior)),
+	}
+}
+
+func newHTMLDelta(ac *accounting.Accounting, d decimal.Decimal) htmlDelta {
+	delta := htmlDelta{Text: signedMoney(ac, d), Sort: d.String()}
+	if d.IsPositive() {
+		delta.Class = "increase"
+	} else if d.IsNegative() {
+		delta.Class = "decrease"
+	}
+	return delta
+}
@@ -0,0 +1,15 @@
+package diff
+
+import (
+	"encoding/json"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+)
+
+// JSONString returns the diff as json
+func JSONString(s *schema.ModularStateDiff) (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
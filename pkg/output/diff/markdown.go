@@ -0,0 +1,256 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/leekchan/accounting"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+// MaxCommentLength is the maximum length of a markdown diff so it fits in a GitHub comment (65536 characters).
+// GitLab and Bitbucket allow longer comments.
+const MaxCommentLength = 65000
+
+var actionIcons = map[schema.Action]string{
+	schema.ActionCreate: "🟢",
+	schema.ActionModify: "🟡",
+	schema.ActionRemove: "🔴",
+}
+
+// markdownModule is a module of the diff flattened with its full path
+type markdownModule struct {
+	path      string
+	diff      schema.ModularStateDiff
+	resources []markdownResource
+}
+
+type markdownResource struct {
+	address string
+	diff    schema.ResourceDiff
+}
+
+// MarkdownString returns the diff as markdown to be posted as a pull request comment.
+// It contains a summary and a collapsible table for each module, with the component details of each resource.
+// If the markdown is longer than maxLength the resources with the smallest cost changes are left out.
+func MarkdownString(s *schema.ModularStateDiff, maxLength int) string {
+	modules := flattenModules("", *s)
+
+	var resources []markdownResource
+	for _, mod := range modules {
+		resources = append(resources, mod.resources...)
+	}
+	// the most significant resources come first
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resourceDelta(resources[i].diff).Abs().GreaterThan(resourceDelta(resources[j].diff).Abs())
+	})
+
+	output := renderMarkdown(s, modules, resources, len(resources))
+	if maxLength <= 0 || len(output) <= maxLength {
+		return output
+	}
+
+	// find the most resources that fit in the max length
+	low, high := 0, len(resources)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		if len(renderMarkdown(s, modules, resources, mid)) <= maxLength {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return renderMarkdown(s, modules, resources, low)
+}
+
+func renderMarkdown(s *schema.ModularStateDiff, modules []markdownModule, sortedResources []markdownResource, keep int) string {
+	ac := cost.MoneyFormatter(s.Currency, 2)
+	kept := make(map[string]bool)
+	for _, res := range sortedResources[:keep] {
+		kept[res.address] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Pennywise cost estimation\n\n")
+	sb.WriteString(summaryLine(s, ac) + "\n\n")
+	if upfront := upfrontLine(s, ac); upfront != "" {
+		sb.WriteString(upfront + "\n\n")
+	}
+	if replacements := replacementsLine(s, ac); replacements != "" {
+		sb.WriteString("> " + replacements + "\n\n")
+	}
+	if unpriced := len(s.UnpricedComponents()); unpriced > 0 {
+		sb.WriteString(fmt.Sprintf("> %s %d components could not be priced and are not included in the costs.\n\n", cost.UnpricedMark, unpriced))
+	}
+
+	for _, mod := range modules {
+		var rows []markdownResource
+		for _, res := range mod.resources {
+			if kept[res.address] {
+				rows = append(rows, res)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return resourceDelta(rows[i].diff).Abs().GreaterThan(resourceDelta(rows[j].diff).Abs())
+		})
+
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>%s <b>%s</b>: %s (%s → %s)</summary>\n\n",
+			actionIcons[mod.diff.Action], mod.path, signedMoney(ac, mod.diff.NewCost.Sub(mod.diff.PriorCost)),
+			ac.FormatMoney(mod.diff.PriorCost), ac.FormatMoney(mod.diff.NewCost)))
+		sb.WriteString("| | Resource | Prior cost | New cost | Delta |\n")
+		sb.WriteString("|---|---|---:|---:|---:|\n")
+		for _, res := range rows {
+			address := fmt.Sprintf("`%s`", res.address)
+			if change := res.diff.PlannedChange; change != nil && change.Note() != "" {
+				address += fmt.Sprintf(" _%s_", change.Note())
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", actionIcons[res.diff.Action], address,
+				ac.FormatMoney(res.diff.PriorCost), ac.FormatMoney(res.diff.NewCost), signedMoney(ac, resourceDelta(res.diff))))
+		}
+		sb.WriteString("\n")
+		for _, res := range rows {
+			sb.WriteString(componentsDetails(res, ac))
+		}
+		sb.WriteString("</details>\n\n")
+	}
+
+	if omitted := len(sortedResources) - keep; omitted > 0 {
+		sb.WriteString(fmt.Sprintf("_%d resources with smaller cost changes are not shown to fit the comment size._\n\n", omitted))
+	}
+	return sb.String()
+}
+
+// summaryLine returns the total cost change and the number of changed resources
+func summaryLine(s *schema.ModularStateDiff, ac *accounting.Accounting) string {
+	counts := make(map[schema.Action]int)
+	countActions(*s, counts)
+
+	delta := s.NewCost.Sub(s.PriorCost)
+	var change string
+	switch {
+	case delta.IsPositive():
+		change = fmt.Sprintf("increase by **%s**", ac.FormatMoney(delta))
+	case delta.IsNegative():
+		change = fmt.Sprintf("decrease by **%s**", ac.FormatMoney(delta.Abs()))
+	default:
+		change = "not change"
+	}
+	return fmt.Sprintf("Monthly cost will %s (%s → %s). %s %d created, %s %d modified, %s %d removed resources.",
+		change, ac.FormatMoney(s.PriorCost), ac.FormatMoney(s.NewCost),
+		actionIcons[schema.ActionCreate], counts[schema.ActionCreate],
+		actionIcons[schema.ActionModify], counts[schema.ActionModify],
+		actionIcons[schema.ActionRemove], counts[schema.ActionRemove])
+}
+
+// replacementsLine returns the cost billed while both the old and the new resources exist in the create before destroy
+// replacements, empty if there are none
+func replacementsLine(s *schema.ModularStateDiff, ac *accounting.Accounting) string {
+	replaced, transitional := s.CreateBeforeDestroyCost()
+	if replaced == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d resources are replaced with create before destroy, %s per month is billed while both the old and the new ones exist.",
+		replaced, ac.FormatMoney(transitional))
+}
+
+// upfrontLine returns the change of the one-time upfront costs, empty if there are no upfront costs
+func upfrontLine(s *schema.ModularStateDiff, ac *accounting.Accounting) string {
+	prior, current := s.UpfrontCosts()
+	if prior.IsZero() && current.IsZero() {
+		return ""
+	}
+	line := fmt.Sprintf("One-time upfront cost: %s → %s (%s), amortized over its term in the monthly costs.",
+		ac.FormatMoney(prior), ac.FormatMoney(current), signedMoney(ac, current.Sub(prior)))
+	if s.UpfrontEstimated() {
+		line += " It's estimated from the reserved instance payment option."
+	}
+	return line
+}
+
+func componentsDetails(res markdownResource, ac *accounting.Accounting) string {
+	if len(res.diff.ComponentDiffs) == 0 {
+		return ""
+	}
+	var names []string
+	for name := range res.diff.ComponentDiffs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<details>\n<summary><code>%s</code> components</summary>\n\n", res.address))
+	sb.WriteString("| | Component | Unit | Prior cost | New cost | Delta |\n")
+	sb.WriteString("|---|---|---|---:|---:|---:|\n")
+	for _, name := range names {
+		for _, c := range res.diff.ComponentDiffs[name] {
+			prior, current := c.Costs()
+			name := c.Component.Name
+			if err := c.PricingError(); err != nil {
+				name = fmt.Sprintf("%s %s _%v_", name, cost.UnpricedMark, err)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n", actionIcons[c.Action], name, c.Component.Unit,
+				ac.FormatMoney(prior), ac.FormatMoney(current), signedMoney(ac, current.Sub(prior))))
+		}
+	}
+	if change := res.diff.PlannedChange; change != nil && len(change.UnknownAttributes) > 0 {
+		sb.WriteString(fmt.Sprintf("\nKnown after apply: %s\n", strings.Join(change.UnknownAttributes, ", ")))
+	}
+	sb.WriteString("\n</details>\n\n")
+	return sb.String()
+}
+
+// flattenModules returns the module and its descendants with their full path
+func flattenModules(path string, s schema.ModularStateDiff) []markdownModule {
+	name := path
+	if name == "" {
+		name = "root module"
+	}
+	mod := markdownModule{path: name, diff: s}
+	for address, res := range s.Resources {
+		if path != "" {
+			address = path + "." + address
+		}
+		mod.resources = append(mod.resources, markdownResource{address: address, diff: res})
+	}
+	modules := []markdownModule{mod}
+
+	var childNames []string
+	for childName := range s.ChildModules {
+		childNames = append(childNames, childName)
+	}
+	sort.Strings(childNames)
+	for _, childName := range childNames {
+		childPath := childName
+		if path != "" {
+			childPath = path + "." + childName
+		}
+		modules = append(modules, flattenModules(childPath, s.ChildModules[childName])...)
+	}
+	return modules
+}
+
+func countActions(s schema.ModularStateDiff, counts map[schema.Action]int) {
+	for _, res := range s.Resources {
+		counts[res.Action]++
+	}
+	for _, child := range s.ChildModules {
+		countActions(child, counts)
+	}
+}
+
+func resourceDelta(res schema.ResourceDiff) decimal.Decimal {
+	return res.NewCost.Sub(res.PriorCost)
+}
+
+// signedMoney formats the amount with an explicit sign for increases
+func signedMoney(ac *accounting.Accounting, d decimal.Decimal) string {
+	if d.IsPositive() {
+		return "+" + ac.FormatMoney(d)
+	}
+	return ac.FormatMoney(d)
+}
@@ -5,8 +5,8 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/kaytu-io/pennywise/pkg/schema"
-	"github.com/leekchan/accounting"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
@@ -22,6 +22,8 @@ type ResourcesModel struct {
 	freeResources        []string
 	unsupportedResources map[string][]string
 	longestName          int
+	// unpricedComponents is the number of components in the module that their price could not be found
+	unpricedComponents int
 }
 
 func (m ResourcesModel) Init() tea.Cmd { return nil }
@@ -40,14 +42,14 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "right", "enter":
 			name := m.table.SelectedRow()[0][11:]
 			if resource, ok := m.state.Resources[name]; ok {
-				compsModel, err := getComponentsModel(name, m.table.SelectedRow()[1], resource.ComponentDiffs, m)
+				compsModel, err := getComponentsModel(name, m.table.SelectedRow()[1], resource, m)
 				if err != nil {
 					panic(err)
 				}
 				return compsModel, cmd
 			} else {
 				module := m.state.ChildModules[name]
-				ac := accounting.Accounting{Symbol: "$", Precision: 2}
+				ac := cost.MoneyFormatter(module.Currency, 2)
 				label := fmt.Sprintf("Total Diff: %s (%s -> %s)", ac.FormatMoney(module.NewCost.Sub(module.PriorCost)),
 					ac.FormatMoney(module.PriorCost), ac.FormatMoney(module.NewCost))
 				var longestName int
@@ -76,6 +78,9 @@ func (m ResourcesModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m ResourcesModel) View() string {
 	output := "Navigate to details by pressing → or [ENTER] Quit by pressing Q or [CTRL+C]\n\n"
 	output += bold.Sprint(m.label) + "\n" + baseStyle.Render(m.table.View()) + "\n"
+	if m.unpricedComponents > 0 {
+		output += fmt.Sprintf("%s %d components could not be priced and are not included in the costs, see the resource details\n", cost.UnpricedMark, m.unpricedComponents)
+	}
 	output += "To learn how to use usage open:\nhttps://github.com/kaytu-io/pennywise/blob/main/docs/usage.md"
 	return output
 }
@@ -97,7 +102,7 @@ func getResourcesModel(label string, stateDiff *schema.ModularStateDiff, longest
 	var rows []table.Row
 	var freeResources []string
 	unsupportedServices := make(map[string][]string)
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
+	ac := cost.MoneyFormatter(stateDiff.Currency, 2)
 
 	for name, module := range stateDiff.ChildModules {
 		var costDiff string
@@ -174,6 +179,6 @@ func getResourcesModel(label string, stateDiff *schema.ModularStateDiff, longest
 		BorderLeft(true).BorderBottom(false).BorderRight(false).BorderTop(false)
 	t.SetStyles(s)
 
-	m := ResourcesModel{label, t, stateDiff, parentModel, freeResources, unsupportedServices, longestName}
+	m := ResourcesModel{label, t, stateDiff, parentModel, freeResources, unsupportedServices, longestName, len(stateDiff.UnpricedComponents())}
 	return m, nil
 }
@@ -4,8 +4,8 @@ import (
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/fatih/color"
+	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/kaytu-io/pennywise/pkg/schema"
-	"github.com/leekchan/accounting"
 	"os"
 )
 
@@ -26,9 +26,17 @@ func ShowStateCosts(s *schema.ModularStateDiff) error {
 		}
 	}
 
-	ac := accounting.Accounting{Symbol: "$", Precision: 2}
+	ac := cost.MoneyFormatter(s.Currency, 2)
 	label := fmt.Sprintf("Total Diff: %s (%s -> %s)", ac.FormatMoney(s.NewCost.Sub(s.PriorCost)),
 		ac.FormatMoney(s.PriorCost), ac.FormatMoney(s.NewCost))
+	if priorUpfront, newUpfront := s.UpfrontCosts(); !priorUpfront.IsZero() || !newUpfront.IsZero() {
+		upfrontTitle := "Upfront Diff"
+		if s.UpfrontEstimated() {
+			upfrontTitle = "Upfront Diff (estimated)"
+		}
+		label += fmt.Sprintf(", %s: %s (%s -> %s)", upfrontTitle, signedMoney(ac, newUpfront.Sub(priorUpfront)),
+			ac.FormatMoney(priorUpfront), ac.FormatMoney(newUpfront))
+	}
 	model, err := getResourcesModel(label, s, longestName, nil)
 	if err != nil {
 		return err
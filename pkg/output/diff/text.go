@@ -0,0 +1,145 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/leekchan/accounting"
+	"sort"
+	"strings"
+)
+
+var underline = color.New(color.Underline)
+
+// CostString returns a string to show the diff of the costs in a non-interactive view
+// containing the modules tree, the resources and their components prior, new and diff costs
+func CostString(s *schema.ModularStateDiff) (string, error) {
+	t := table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.Style().Options.SeparateRows = false
+	t.Style().Options.SeparateHeader = false
+	t.Style().Format.Header = text.FormatDefault
+
+	t.AppendHeader(table.Row{
+		underline.Sprint("Name"),
+		underline.Sprint("Unit"),
+		underline.Sprint("Prior Monthly Cost"),
+		underline.Sprint("New Monthly Cost"),
+		underline.Sprint("Diff"),
+	})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 2, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 3, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 4, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 5, Align: text.AlignRight, AlignHeader: text.AlignRight},
+	})
+	t.AppendRow(table.Row{""})
+
+	ac := cost.MoneyFormatter(s.Currency, 2)
+	unsupportedServices := make(map[string]bool)
+	appendModuleDiffRows(t, ac, *s, 0, unsupportedServices)
+
+	costString := t.Render()
+	costString += "\n──────────────────────────────────\n"
+	costString += fmt.Sprintf("%s:    %s (%s -> %s)", bold.Sprint("Total Cost Diff (per month)"),
+		signedMoney(ac, s.NewCost.Sub(s.PriorCost)), ac.FormatMoney(s.PriorCost), ac.FormatMoney(s.NewCost))
+	if priorUpfront, newUpfront := s.UpfrontCosts(); !priorUpfront.IsZero() || !newUpfront.IsZero() {
+		upfrontDelta := signedMoney(ac, newUpfront.Sub(priorUpfront))
+		upfrontTitle := "Upfront Cost Diff (one-time)"
+		if s.UpfrontEstimated() {
+			upfrontTitle = "Upfront Cost Diff (one-time, estimated)"
+		}
+		costString += fmt.Sprintf("\n%s:    %s (%s -> %s)", bold.Sprint(upfrontTitle),
+			upfrontDelta, ac.FormatMoney(priorUpfront), ac.FormatMoney(newUpfront))
+	}
+	if replaced, transitional := s.CreateBeforeDestroyCost(); replaced > 0 {
+		costString = fmt.Sprintf("%s\n- %d resources are replaced with create before destroy, %s per month is billed while both the old and the new ones exist",
+			costString, replaced, ac.FormatMoney(transitional))
+	}
+	if unpriced := len(s.UnpricedComponents()); unpriced > 0 {
+		costString = fmt.Sprintf("%s\n- %s %d components could not be priced", costString, cost.UnpricedMark, unpriced)
+	}
+
+	var unsupported []string
+	for typ := range unsupportedServices {
+		unsupported = append(unsupported, typ)
+	}
+	sort.Strings(unsupported)
+	if len(unsupported) > 0 {
+		costString = fmt.Sprintf("%s\n- Resource types %s not supported", costString, strings.Join(unsupported, ", "))
+	}
+	return costString, nil
+}
+
+// appendModuleDiffRows appends the rows of the module resources and child modules, indented by the module depth,
+// with the costs formatted by ac
+func appendModuleDiffRows(t table.Writer, ac *accounting.Accounting, s schema.ModularStateDiff, depth int, unsupportedServices map[string]bool) {
+	indent := strings.Repeat("  ", depth)
+
+	var resources []schema.ResourceDiff
+	for address, res := range s.Resources {
+		if !res.IsSupported && res.Type != "" {
+			unsupportedServices[res.Type] = true
+			continue
+		}
+		res.Address = address
+		resources = append(resources, res)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].NewCost.Sub(resources[i].PriorCost).Abs().GreaterThan(resources[j].NewCost.Sub(resources[j].PriorCost).Abs())
+	})
+	for _, res := range resources {
+		t.AppendRow(table.Row{indent + actionSign(res.Action) + bold.Sprint(res.Address), "",
+			ac.FormatMoney(res.PriorCost), ac.FormatMoney(res.NewCost), signedMoney(ac, res.NewCost.Sub(res.PriorCost))})
+		for _, note := range res.PlannedChangeNotes(3) {
+			t.AppendRow(table.Row{indent + faint.Sprint("   "+note)})
+		}
+
+		var names []string
+		for name := range res.ComponentDiffs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, c := range res.ComponentDiffs[name] {
+				prior, current := c.Costs()
+				name := c.Component.Name
+				if err := c.PricingError(); err != nil {
+					name = fmt.Sprintf("%s %s (%v)", name, cost.UnpricedMark, err)
+				}
+				t.AppendRow(table.Row{indent + faint.Sprint("└─ ") + actionSign(c.Action) + name, c.Component.Unit,
+					ac.FormatMoney(prior), ac.FormatMoney(current), signedMoney(ac, current.Sub(prior))})
+			}
+		}
+	}
+
+	var moduleNames []string
+	for name := range s.ChildModules {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+	for _, name := range moduleNames {
+		module := s.ChildModules[name]
+		t.AppendRow(table.Row{indent + actionSign(module.Action) + bold.Sprint(name), "",
+			ac.FormatMoney(module.PriorCost), ac.FormatMoney(module.NewCost), signedMoney(ac, module.NewCost.Sub(module.PriorCost))})
+		appendModuleDiffRows(t, ac, module, depth+1, unsupportedServices)
+	}
+}
+
+func actionSign(action schema.Action) string {
+	switch action {
+	case schema.ActionCreate:
+		return green.Sprint("+ ")
+	case schema.ActionModify:
+		return yellow.Sprint("~ ")
+	case schema.ActionRemove:
+		return red.Sprint("- ")
+	default:
+		return "  "
+	}
+}
@@ -9,6 +9,7 @@ import (
 )
 
 var bold = color.New(color.Bold)
+var faint = color.New(color.Faint)
 
 func sortRows(rows []table.Row) []table.Row {
 	sort.Slice(rows, func(i, j int) bool {
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHTMLReportPath is the path of the html report if none is given
+const DefaultHTMLReportPath = "pennywise-report.html"
+
+// maxChartBars is the maximum number of bars in a chart, the smaller ones are summed up as others
+const maxChartBars = 12
+
+// ChartBar is a single bar of a chart in the html report
+type ChartBar struct {
+	Label string
+	Value decimal.Decimal
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: 0.2rem; }
+.generated { color: #656d76; margin-top: 0; }
+.summary { font-size: 1.2rem; margin: 1rem 0; }
+.charts { display: flex; flex-wrap: wrap; gap: 2rem; margin: 1rem 0 2rem; }
+.chart h3 { margin: 0 0 0.5rem; font-size: 1rem; }
+.chart text { font-size: 12px; fill: #1f2328; }
+.chart rect { fill: #0969da; }
+details { margin: 0.3rem 0 0.3rem 1rem; }
+details > summary { cursor: pointer; padding: 0.2rem 0; }
+table { border-collapse: collapse; margin: 0.5rem 0 1rem; }
+th, td { border-bottom: 1px solid #d0d7de; padding: 0.3rem 0.8rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+table.sortable > thead th { cursor: pointer; user-select: none; }
+table.sortable > thead th::after { content: " \2195"; color: #8c959f; }
+td.number, th.number { text-align: right; font-variant-numeric: tabular-nums; }
+td details { margin: 0; }
+.increase { color: #cf222e; }
+.decrease { color: #1a7f37; }
+.muted { color: #656d76; }
+.warning { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="generated">Generated by pennywise on {{.Date}}</p>
+{{.Body}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, index) {
+    var ascending = false;
+    th.addEventListener("click", function () {
+      ascending = !ascending;
+      var tbody = table.tBodies[0];
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[index].getAttribute("data-sort") || a.cells[index].textContent.trim();
+        var y = b.cells[index].getAttribute("data-sort") || b.cells[index].textContent.trim();
+        var nx = parseFloat(x), ny = parseFloat(y);
+        var result = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+        return ascending ? result : -result;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+    });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// HTMLPage returns a self-contained html page with the body, the tables with the sortable class can be sorted
+// by clicking on their headers
+func HTMLPage(title string, body template.HTML) (string, error) {
+	var sb strings.Builder
+	err := pageTemplate.Execute(&sb, struct {
+		Title string
+		Date  string
+		Body  template.HTML
+	}{title, time.Now().Format(time.RFC1123), body})
+	if err != nil {
+		return "", fmt.Errorf("failed to render html report: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// WriteHTMLReport writes the html page to the path, or to DefaultHTMLReportPath if the path is empty
+func WriteHTMLReport(path, page string) (string, error) {
+	if path == "" {
+		path = DefaultHTMLReportPath
+	}
+	err := os.WriteFile(path, []byte(page), 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write html report: %w", err)
+	}
+	return path, nil
+}
+
+var chartTemplate = template.Must(template.New("chart").Parse(`<div class="chart">
+<h3>{{.Title}}</h3>
+<svg width="{{.Width}}" height="{{.Height}}" role="img" aria-label="{{.Title}}">
+{{range .Bars}}<text x="0" y="{{.TextY}}">{{.Label}}</text>
+<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="16"></rect>
+<text x="{{.ValueX}}" y="{{.TextY}}">{{.Value}}</text>
+{{end}}</svg>
+</div>
+`))
+
+// BarChart returns an svg horizontal bar chart of the values, the values are formatted with format.
+// Only the biggest bars are shown and the rest are summed up as others.
+func BarChart(title string, bars []ChartBar, format func(decimal.Decimal) string) (template.HTML, error) {
+	if len(bars) > maxChartBars {
+		others := ChartBar{Label: "others"}
+		for _, bar := range bars[maxChartBars-1:] {
+			others.Value = others.Value.Add(bar.Value)
+		}
+		bars = append(append([]ChartBar{}, bars[:maxChartBars-1]...), others)
+	}
+
+	const labelWidth, barWidth, valueWidth, rowHeight = 220, 260, 110, 22
+	maxValue := decimal.Zero
+	for _, bar := range bars {
+		if bar.Value.GreaterThan(maxValue) {
+			maxValue = bar.Value
+		}
+	}
+
+	type chartRow struct {
+		Label, Value               string
+		X, Y, TextY, Width, ValueX int
+	}
+	var rows []chartRow
+	for i, bar := range bars {
+		width := 0
+		if maxValue.IsPositive() && bar.Value.IsPositive() {
+			width = int(bar.Value.Div(maxValue).Mul(decimal.NewFromInt(barWidth)).IntPart())
+		}
+		label := bar.Label
+		if runes := []rune(label); len(runes) > 32 {
+			label = string(runes[:31]) + "…"
+		}
+		rows = append(rows, chartRow{
+			Label:  label,
+			Value:  format(bar.Value),
+			X:      labelWidth,
+			Y:      i * rowHeight,
+			TextY:  i*rowHeight + 13,
+			Width:  width,
+			ValueX: labelWidth + width + 6,
+		})
+	}
+
+	var sb strings.Builder
+	err := chartTemplate.Execute(&sb, struct {
+		Title         string
+		Width, Height int
+		Bars          []chartRow
+	}{title, labelWidth + barWidth + valueWidth, len(rows) * rowHeight, rows})
+	if err != nil {
+		return "", fmt.Errorf("failed to render chart %s: %w", title, err)
+	}
+	return template.HTML(sb.String()), nil
+}
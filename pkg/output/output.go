@@ -0,0 +1,31 @@
+package output
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"github.com/muesli/termenv"
+	"golang.org/x/crypto/ssh/terminal"
+	"os"
+)
+
+// Output formats of the costs and diffs
+const (
+	// Interactive shows the interactive view, or the classic view if the terminal is not interactive
+	Interactive = ""
+	Markdown    = "markdown"
+	JSON        = "json"
+	// HTML writes a static html report to a file
+	HTML = "html"
+)
+
+// IsInteractive checks if both stdin and stdout are terminals so the interactive view can be shown
+func IsInteractive() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// DisableColors removes the colors from the classic and the interactive views.
+// The colors are also disabled if the NO_COLOR environment variable is set or stdout is not a terminal.
+func DisableColors() {
+	color.NoColor = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
@@ -0,0 +1,58 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// UnknownAttributes returns the paths of the attributes that are known only after apply sorted by the path,
+// the nested attributes are separated by dots like root_block_device.0.volume_id
+func (c Change) UnknownAttributes() []string {
+	var paths []string
+	unknownPaths(c.AfterUnknown, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func unknownPaths(value interface{}, path string, paths *[]string) {
+	switch v := value.(type) {
+	case bool:
+		if v && path != "" {
+			*paths = append(*paths, path)
+		}
+	case map[string]interface{}:
+		for k, child := range v {
+			unknownPaths(child, joinPath(path, k), paths)
+		}
+	case []interface{}:
+		for i, child := range v {
+			unknownPaths(child, joinPath(path, strconv.Itoa(i)), paths)
+		}
+	}
+}
+
+// replacePaths returns the paths of the attributes forcing the replacement joined by dots
+func (c Change) replacePaths() []string {
+	var paths []string
+	for _, parts := range c.ReplacePaths {
+		var path string
+		for _, part := range parts {
+			switch p := part.(type) {
+			case float64:
+				path = joinPath(path, strconv.Itoa(int(p)))
+			default:
+				path = joinPath(path, fmt.Sprint(p))
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,325 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a problem found in the plan that doesn't stop the parsing
+type Diagnostic struct {
+	// Address is the configuration address of the resource the problem is found on
+	Address string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Address, d.Message)
+}
+
+// reference is a reference to an attribute of another resource read from a *ref* value
+type reference struct {
+	// address is the configuration address of the referred resource
+	address string
+	// key is the instance key given in the reference, nil if it's not given
+	key       interface{}
+	attribute string
+}
+
+// parseReference returns the reference of a *ref* value like *ref*.aws_subnet.this["a"].id,
+// the address is resolved relatively to the module of the resource with the reference first
+func parseReference(value, prefix string, resourcesMap map[string][]Resource) (reference, bool) {
+	if !strings.HasPrefix(value, "*ref*.") {
+		return reference{}, false
+	}
+	value = strings.TrimPrefix(value, "*ref*.")
+	dot := strings.LastIndex(value, ".")
+	if dot < 0 {
+		return reference{}, false
+	}
+	ref := reference{attribute: value[dot+1:]}
+	address := value[:dot]
+	if match := lastInstanceKeyRegex.FindStringSubmatch(address); match != nil {
+		if key, err := strconv.Unquote(match[1]); err == nil {
+			ref.key = key
+		} else if key, err := strconv.Atoi(match[1]); err == nil {
+			ref.key = key
+		}
+	}
+	address = configAddress(address)
+	if _, ok := resourcesMap[prefix+address]; ok {
+		ref.address = prefix + address
+	} else {
+		ref.address = address
+	}
+	return ref, true
+}
+
+// dependencies returns the configuration addresses of the resources the values of the resource at the address
+// refer to, including the resource its for_each iterates over, sorted by the address
+func dependencies(address string, resourcesMap map[string][]Resource, resourceProviders map[string]providerWithResourceValues) []string {
+	set := make(map[string]bool)
+	if forEachResource := resourceProviders[address].ForEachResource; forEachResource != "" {
+		set[forEachResource] = true
+	}
+	for _, res := range resourcesMap[address] {
+		prefix := modulePrefix(address, res.Type, res.Name)
+		walkStrings(res.Values, func(value string) {
+			if ref, ok := parseReference(value, prefix, resourcesMap); ok {
+				set[ref.address] = true
+			}
+		})
+	}
+	var deps []string
+	for dep := range set {
+		if _, ok := resourcesMap[dep]; ok && dep != address {
+			deps = append(deps, dep)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// resolveReferences resolves the references of the resources in the order of their dependencies so every resource
+// is resolved once after the resources it refers to. The resources in reference cycles are resolved after the
+// resources they refer to outside of the cycles with the values involved in the cycles left unknown, and the cycles
+// and the resources depending on them are returned as diagnostics.
+func resolveReferences(resourcesMap map[string][]Resource, resourceProviders map[string]providerWithResourceValues) []Diagnostic {
+	addresses := make([]string, 0, len(resourcesMap))
+	for address, resources := range resourcesMap {
+		addresses = append(addresses, address)
+		for _, res := range resources {
+			res.Values["id"] = fmt.Sprintf("%s.id", res.Address)
+		}
+	}
+	sort.Strings(addresses)
+
+	// Kahn's algorithm, the dependents of a resource are resolved after it
+	deps := make(map[string][]string, len(addresses))
+	inDegree := make(map[string]int, len(addresses))
+	dependents := make(map[string][]string)
+	for _, address := range addresses {
+		deps[address] = dependencies(address, resourcesMap, resourceProviders)
+		inDegree[address] = len(deps[address])
+		for _, dep := range deps[address] {
+			dependents[dep] = append(dependents[dep], address)
+		}
+	}
+	var queue []string
+	for _, address := range addresses {
+		if inDegree[address] == 0 {
+			queue = append(queue, address)
+		}
+	}
+	resolved := make(map[string]bool, len(addresses))
+	for len(queue) > 0 {
+		address := queue[0]
+		queue = queue[1:]
+		resolveResourceReferences(address, resourcesMap, resourceProviders)
+		resolved[address] = true
+		for _, dependent := range dependents[address] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// The resources left are in cycles or depend on them, the strongly connected components of what's left
+	// are the cycles and they're found in the order of their dependencies
+	left := make(map[string]bool)
+	for _, address := range addresses {
+		if !resolved[address] {
+			left[address] = true
+		}
+	}
+	var diagnostics []Diagnostic
+	for _, component := range stronglyConnectedComponents(addresses, deps, resolved) {
+		if len(component) > 1 {
+			for _, address := range component {
+				var others []string
+				for _, other := range component {
+					if other != address {
+						others = append(others, other)
+					}
+				}
+				diagnostics = append(diagnostics, Diagnostic{
+					Address: address,
+					Message: fmt.Sprintf("reference cycle with %s, the values referring to them are unknown", strings.Join(others, ", ")),
+				})
+			}
+		} else {
+			address := component[0]
+			var through []string
+			for _, dep := range deps[address] {
+				if left[dep] {
+					through = append(through, dep)
+				}
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Address: address,
+				Message: fmt.Sprintf("depends on a reference cycle through %s, the values coming from the cycle are unknown", strings.Join(through, ", ")),
+			})
+		}
+		for _, address := range component {
+			resolveResourceReferences(address, resourcesMap, resourceProviders)
+			resolved[address] = true
+		}
+	}
+	return diagnostics
+}
+
+// stronglyConnectedComponents returns the strongly connected components of the graph of the addresses that are
+// not resolved, using Tarjan's algorithm. A component is returned after the components it depends on and the
+// addresses of each component are sorted.
+func stronglyConnectedComponents(addresses []string, deps map[string][]string, resolved map[string]bool) [][]string {
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var connect func(address string)
+	connect = func(address string) {
+		index[address] = len(index)
+		lowLink[address] = index[address]
+		stack = append(stack, address)
+		onStack[address] = true
+		for _, dep := range deps[address] {
+			if resolved[dep] {
+				continue
+			}
+			if _, ok := index[dep]; !ok {
+				connect(dep)
+				if lowLink[dep] < lowLink[address] {
+					lowLink[address] = lowLink[dep]
+				}
+			} else if onStack[dep] && index[dep] < lowLink[address] {
+				lowLink[address] = index[dep]
+			}
+		}
+		if lowLink[address] != index[address] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == address {
+				break
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	for _, address := range addresses {
+		if _, ok := index[address]; !ok && !resolved[address] {
+			connect(address)
+		}
+	}
+	return components
+}
+
+// resolveResourceReferences resolves the references of the instances of the resource at the configuration address
+// to the values of the resources they refer to. The references to a resource with many instances and the each.key,
+// each.value and count.index of an instance are resolved by the instance key. The values that can't be resolved
+// are set to nil since they're unknown until apply.
+func resolveResourceReferences(address string, resourcesMap map[string][]Resource, resourceProviders map[string]providerWithResourceValues) {
+	pwrv := resourceProviders[address]
+	for _, res := range resourcesMap[address] {
+		prefix := modulePrefix(address, res.Type, res.Name)
+		key := res.InstanceKey()
+		resolve := func(value string) interface{} {
+			if ref, ok := parseReference(value, prefix, resourcesMap); ok {
+				return resolveReference(ref, key, resourcesMap)
+			}
+			if path := strings.Split(value, "."); path[0] == "*each*" && len(path) > 1 {
+				return resolveEach(path[1:], key, pwrv, resourcesMap)
+			}
+			return nil
+		}
+		// The nested values are copied while they're resolved since the values from the configuration
+		// are shared between the instances of the resource
+		for k, val := range res.Values {
+			res.Values[k] = resolveValue(val, resolve)
+		}
+	}
+}
+
+// walkStrings calls fn with every string of the value, including the ones nested in maps and lists
+func walkStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, e := range v {
+			walkStrings(e, fn)
+		}
+	case []interface{}:
+		for _, e := range v {
+			walkStrings(e, fn)
+		}
+	}
+}
+
+// resolveValue returns the value with every *ref* and *each* value in it, including the ones nested in maps
+// and lists, replaced by what resolve returns for it. The maps and lists are copied.
+func resolveValue(value interface{}, resolve func(string) interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if isUnresolved(v) {
+			return resolve(v)
+		}
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = resolveValue(e, resolve)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = resolveValue(e, resolve)
+		}
+		return l
+	}
+	return value
+}
+
+// clearUnresolved returns the value with the references in it that are not resolved set to nil
+func clearUnresolved(value interface{}) interface{} {
+	return resolveValue(value, func(string) interface{} { return nil })
+}
+
+// resolveReference returns the value of the attribute of the referred instance, the instance with the given key
+// or the same key as the resource with the reference, or the only instance of the resource
+func resolveReference(ref reference, key interface{}, resourcesMap map[string][]Resource) interface{} {
+	instances := resourcesMap[ref.address]
+	if len(instances) == 0 {
+		return nil
+	}
+	if ref.key != nil {
+		key = ref.key
+	}
+	instance, ok := findInstance(instances, key)
+	if !ok {
+		if ref.key != nil {
+			return nil
+		}
+		instance = instances[0]
+	}
+	value, ok := instance.Values[ref.attribute]
+	if !ok {
+		return nil
+	}
+	return clearUnresolved(value)
+}
+
+// isUnresolved checks if the value is a reference that's not resolved, e.g. because of a cycle
+func isUnresolved(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && (strings.HasPrefix(s, "*ref*.") || strings.HasPrefix(s, "*each*."))
+}
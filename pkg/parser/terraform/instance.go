@@ -0,0 +1,115 @@
+package terraform
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// instanceKeyRegex matches the instance keys of the resources and modules in an address,
+// numeric ones for count and quoted strings for for_each like [0] or ["a"]
+var instanceKeyRegex = regexp.MustCompile(`\[(?:\d+|"(?:[^"\\]|\\.)*")\]`)
+
+// lastInstanceKeyRegex matches the instance key at the end of a resource address
+var lastInstanceKeyRegex = regexp.MustCompile(`\[(\d+|"(?:[^"\\]|\\.)*")\]$`)
+
+// configAddress returns the address of the resource in the configuration, without the instance keys
+// of the resource and its modules, e.g. module.a["x"].aws_instance.b[0] becomes module.a.aws_instance.b
+func configAddress(address string) string {
+	return instanceKeyRegex.ReplaceAllString(address, "")
+}
+
+// modulePrefix returns the module part of the configuration address of the resource with a trailing dot,
+// empty for the resources of the root module
+func modulePrefix(configAddr, resourceType, name string) string {
+	return strings.TrimSuffix(configAddr, fmt.Sprintf("%s.%s", resourceType, name))
+}
+
+// InstanceKey returns the instance key of the resource, an int for the resources with count and a string for
+// the resources with for_each, nil if the resource has a single instance.
+// The index of the plan is used if it's set, otherwise it's read from the address.
+func (r Resource) InstanceKey() interface{} {
+	switch index := r.Index.(type) {
+	case float64:
+		return int(index)
+	case int, string:
+		return index
+	}
+	match := lastInstanceKeyRegex.FindStringSubmatch(r.Address)
+	if match == nil {
+		return nil
+	}
+	if key, err := strconv.Unquote(match[1]); err == nil {
+		return key
+	}
+	if key, err := strconv.Atoi(match[1]); err == nil {
+		return key
+	}
+	return nil
+}
+
+// sameInstanceKey checks if the instance keys are the same
+func sameInstanceKey(key1, key2 interface{}) bool {
+	if key1 == nil || key2 == nil {
+		return key1 == key2
+	}
+	return fmt.Sprint(key1) == fmt.Sprint(key2)
+}
+
+// findInstance returns the instance of the resource with the key
+func findInstance(instances []Resource, key interface{}) (Resource, bool) {
+	for _, instance := range instances {
+		if sameInstanceKey(instance.InstanceKey(), key) {
+			return instance, true
+		}
+	}
+	return Resource{}, false
+}
+
+// lookupPath returns the nested value at the path of the attributes
+func lookupPath(value interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil, false
+		}
+		return lookupPath(child, path[1:])
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return lookupPath(v[index], path[1:])
+	}
+	return nil, false
+}
+
+// eachValue returns the each.value of the instance with the key from the evaluated for_each of the resource.
+// The value of a for_each over a map is the element of the key, over a set of strings it's the key itself
+// and over another resource it's the values of the instance of that resource with the same key.
+func eachValue(forEach interface{}, forEachResource []Resource, key interface{}) (interface{}, bool) {
+	if forEachResource != nil {
+		instance, ok := findInstance(forEachResource, key)
+		if !ok {
+			return nil, false
+		}
+		return instance.Values, true
+	}
+	switch v := forEach.(type) {
+	case map[string]interface{}:
+		value, ok := v[fmt.Sprint(key)]
+		return value, ok
+	case []interface{}:
+		for _, element := range v {
+			if sameInstanceKey(element, key) {
+				return element, true
+			}
+		}
+	}
+	return nil, false
+}
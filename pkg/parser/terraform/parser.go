@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"github.com/kaytu-io/pennywise/pkg/usage"
 	"io"
-	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -16,15 +16,24 @@ type Plan struct {
 	providerInitializers map[string]ProviderInitializer
 	usage                usage.Usage
 
+	// diagnostics are the problems found while extracting the resources that don't stop the parsing
+	diagnostics []Diagnostic
+
 	Configuration Configuration       `json:"configuration"`
 	PriorState    *State              `json:"prior_state"`
 	PlannedValues Values              `json:"planned_values"`
 	Variables     map[string]Variable `json:"variables"`
+	// ResourceChanges are the actions and the unknown values of the planned changes of each resource
+	ResourceChanges []ResourceChange `json:"resource_changes"`
 }
 
 // SetUsage will set the usage of the plan
 func (p *Plan) SetUsage(u usage.Usage) { p.usage = u }
 
+// Diagnostics returns the problems found while extracting the resources, like reference cycles,
+// the values involved are left unknown.
+func (p *Plan) Diagnostics() []Diagnostic { return p.diagnostics }
+
 // NewPlan returns an empty Plan.
 func NewPlan(providerInitializers ...ProviderInitializer) *Plan {
 	piMap := make(map[string]ProviderInitializer)
@@ -55,9 +64,24 @@ func (p *Plan) ExtractPlannedQueries() ([]Resource, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract queries: %w", err)
 	}
+	p.setChanges(q)
 	return q, nil
 }
 
+// setChanges sets the planned changes of the resources from the resource_changes of the plan
+func (p *Plan) setChanges(resources []Resource) {
+	changes := make(map[string]Change, len(p.ResourceChanges))
+	for _, rc := range p.ResourceChanges {
+		changes[rc.Address] = rc.Change
+	}
+	for i, res := range resources {
+		if change, ok := changes[res.Address]; ok {
+			change := change
+			resources[i].Change = &change
+		}
+	}
+}
+
 // ExtractPriorQueries extracts a query.Resource slice from the `prior_state` part of the Plan.
 func (p *Plan) ExtractPriorQueries() ([]Resource, error) {
 	if p.PriorState == nil {
@@ -112,17 +136,7 @@ func (p *Plan) extractResources(values Values, providers map[string]Provider) ([
 	}
 
 	resourcesMap := p.extractModuleResources(&values.RootModule, resourceProviders)
-
-	var retry int
-	var oldResourcesMap map[string][]Resource
-	for retry < 50 {
-		resourcesMap = p.extractReferences(resourcesMap)
-		if mapsEqual(oldResourcesMap, resourcesMap) {
-			break
-		}
-		oldResourcesMap = resourcesMap
-		retry++
-	}
+	p.diagnostics = append(p.diagnostics, resolveReferences(resourcesMap, resourceProviders)...)
 
 	var resources []Resource
 	for _, rss := range resourcesMap {
@@ -134,39 +148,34 @@ func (p *Plan) extractResources(values Values, providers map[string]Provider) ([
 type providerWithResourceValues struct {
 	Provider Provider
 	Values   map[string]interface{}
+	// ForEach is the evaluated for_each of the resource, nil if it's not set or not known
+	ForEach interface{}
+	// ForEachResource is the configuration address of the resource the for_each iterates over, empty if it doesn't
+	ForEachResource string
 }
 
-func (p *Plan) extractReferences(resourcesMap map[string][]Resource) map[string][]Resource {
-	for _, resources := range resourcesMap {
-		for i, res := range resources {
-			res.Values["id"] = fmt.Sprintf("%s.id", res.Address)
-			for key, val := range res.Values {
-				if value, ok := val.(string); ok {
-					ref := strings.Split(value, ".")
-					if ref[0] == "*ref*" && len(ref) > 2 {
-						if _, ok := resourcesMap[strings.Join(ref[1:len(ref)-1], ".")]; ok {
-							if refValue, ok := resourcesMap[strings.Join(ref[1:len(ref)-1], ".")][0].Values[ref[len(ref)-1]]; ok {
-								res.Values[key] = refValue
-							}
-						} else {
-							res.Values[key] = nil
-						}
-					} else if ref[0] == "*each*" && len(ref) > 2 {
-						if _, ok := resourcesMap[strings.Join(ref[1:len(ref)-1], ".")]; ok && len(resourcesMap[strings.Join(ref[1:len(ref)-1], ".")]) > i {
-							if refValue, ok := resourcesMap[strings.Join(ref[1:len(ref)-1], ".")][i].Values[ref[len(ref)-1]]; ok {
-								res.Values[key] = refValue
-							}
-						} else {
-							res.Values[key] = nil
-						}
-					}
-				} else {
-					continue
-				}
-			}
+// resolveEach returns the value of each.key (or count.index) and each.value with the path of its attributes
+// for the instance with the key, nil if it can't be resolved
+func resolveEach(path []string, key interface{}, pwrv providerWithResourceValues, resourcesMap map[string][]Resource) interface{} {
+	switch path[0] {
+	case "key":
+		return key
+	case "value":
+		var forEachResource []Resource
+		if pwrv.ForEachResource != "" {
+			forEachResource = resourcesMap[pwrv.ForEachResource]
+		}
+		value, ok := eachValue(pwrv.ForEach, forEachResource, key)
+		if !ok {
+			return nil
 		}
+		value, ok = lookupPath(value, path[1:])
+		if !ok {
+			return nil
+		}
+		return clearUnresolved(value)
 	}
-	return resourcesMap
+	return nil
 }
 
 // extractModuleConfiguration iterates over all the modules included in the plan's configuration block and
@@ -192,13 +201,16 @@ func (p *Plan) extractModuleConfiguration(prefix string, module *ConfigurationMo
 
 		if prov, ok := providers[key]; ok {
 			resPrefix := fmt.Sprintf("module.%s", prefix)
-			rv, err := p.evaluateResourceExpressions(resPrefix, res.ForEachExpression, res.Expressions, module.Variables)
+			rv, err := p.evaluateResourceExpressions(resPrefix, res.Expressions, module.Variables)
 			if err != nil {
 				return fmt.Errorf("failed to evaluate resource expresions: %w", err)
 			}
+			forEach, forEachResource := p.evaluateForEach(prefix, res.ForEachExpression, module.Variables)
 			resourceProviders[addr] = providerWithResourceValues{
-				Provider: prov,
-				Values:   rv,
+				Provider:        prov,
+				Values:          rv,
+				ForEach:         forEach,
+				ForEachResource: forEachResource,
 			}
 		}
 	}
@@ -207,7 +219,7 @@ func (p *Plan) extractModuleConfiguration(prefix string, module *ConfigurationMo
 		if child.Module != nil {
 			nextPrefix := k
 			if prefix != "" {
-				nextPrefix = fmt.Sprintf("%s.%s", prefix, k)
+				nextPrefix = fmt.Sprintf("%s.module.%s", prefix, k)
 			}
 			err := p.extractModuleConfiguration(nextPrefix, child.Module, providers, resourceProviders)
 			if err != nil {
@@ -224,25 +236,9 @@ func (p *Plan) extractModuleResources(module *Module, resourceProviders map[stri
 	rss := make(map[string]Resource)
 	resources := make(map[string][]Resource)
 	for _, tfres := range module.Resources {
-		arrayRegex := regexp.MustCompile(`^([^[]+)(?:\[(\d+)\])?$`)
-		arrayMatch := arrayRegex.FindStringSubmatch(tfres.Address)
-		var pwrv providerWithResourceValues
-		if arrayMatch != nil {
-			if arrayMatch[2] != "" {
-				pwrvTemp, ok := resourceProviders[arrayMatch[1]]
-				if ok {
-					pwrv = pwrvTemp
-				}
-			}
-		}
-		pwrvTemp, ok := resourceProviders[fmt.Sprintf("%s.%s", tfres.Type, tfres.Name)]
-		if !ok {
-			pwrvTemp, ok = resourceProviders[tfres.Address]
-			if ok {
-				pwrv = pwrvTemp
-			}
-		} else {
-			pwrv = pwrvTemp
+		pwrv := resourceProviders[configAddress(tfres.Address)]
+		if tfres.Values == nil {
+			tfres.Values = make(map[string]interface{})
 		}
 		for k, v := range pwrv.Values {
 			if v == nil {
@@ -251,9 +247,6 @@ func (p *Plan) extractModuleResources(module *Module, resourceProviders map[stri
 
 			vv, ok := tfres.Values[k]
 			if !ok {
-				if len(tfres.Values) == 0 {
-					tfres.Values = make(map[string]interface{})
-				}
 				tfres.Values[k] = v
 				continue
 			}
@@ -299,8 +292,8 @@ func (p *Plan) extractModuleResources(module *Module, resourceProviders map[stri
 	}
 
 	for _, rs := range rss {
-		// We know it's present as it has passed the previous loop
-		name := fmt.Sprintf("%s.%s", rs.Type, rs.Name)
+		// The instances of the resources with count or for_each share the configuration address
+		name := configAddress(rs.Address)
 		if _, ok := resources[name]; ok {
 			resources[name] = append(resources[name], rs)
 		} else {
@@ -318,6 +311,11 @@ func (p *Plan) extractModuleResources(module *Module, resourceProviders map[stri
 			}
 		}
 	}
+	for _, instances := range resources {
+		sort.Slice(instances, func(i, j int) bool {
+			return instances[i].Address < instances[j].Address
+		})
+	}
 
 	return resources
 }
@@ -353,7 +351,7 @@ func (p *Plan) evaluateProviderConfigExpressions(config ProviderConfig) (map[str
 
 // evaluateResourceExpressions returns evaluated values of resource's configuration block, whether a constant
 // value or reference to a variable.
-func (p *Plan) evaluateResourceExpressions(prefix string, forEach map[string]interface{}, config map[string]interface{}, variables map[string]Variable) (map[string]interface{}, error) {
+func (p *Plan) evaluateResourceExpressions(prefix string, config map[string]interface{}, variables map[string]Variable) (map[string]interface{}, error) {
 	values := make(map[string]interface{})
 	for name, ex := range config {
 		m, ok := ex.(map[string]interface{})
@@ -372,7 +370,7 @@ func (p *Plan) evaluateResourceExpressions(prefix string, forEach map[string]int
 					// that can be defined multiple times so it should always be map[]
 					continue
 				}
-				av, err := p.evaluateResourceExpressions(prefix, forEach, mc, variables)
+				av, err := p.evaluateResourceExpressions(prefix, mc, variables)
 				if err != nil {
 					return nil, fmt.Errorf("failed to evaluateResourceExpressions on array: %w", err)
 				}
@@ -401,26 +399,21 @@ func (p *Plan) evaluateResourceExpressions(prefix string, forEach map[string]int
 		if len(ref) < 2 {
 			return nil, fmt.Errorf("refernce %q has invalid format", refs[0])
 		}
+		// each.key, each.value and count.index are resolved by the instance key of the resource with the references
 		if ref[0] == "each" {
 			if ref[1] == "key" {
-				values[name] = fmt.Sprintf("*each*.%s.key", forEach["references"].([]interface{})[0])
-				continue
-			} else if ref[1] == "value" && len(ref) == 2 {
-				values[name] = fmt.Sprintf("*each*.%s", forEach["references"].([]interface{})[0])
-				continue
-			} else if strings.Contains(ref[1], "value") && len(ref) == 2 {
-				regex := regexp.MustCompile(`^value\["([^"]+)"\]$`)
-				match := regex.FindStringSubmatch(ref[1])
-				if match != nil {
-					values[name] = fmt.Sprintf("*each*.%s[%s]", forEach["references"].([]interface{})[0], match[1])
-					continue
-				}
-			}
-			if forEach["references"] != nil {
-				values[name] = fmt.Sprintf("*each*.%s.%s", forEach["references"].([]interface{})[0], ref[2])
+				values[name] = "*each*.key"
+			} else if ref[1] == "value" {
+				values[name] = strings.Join(append([]string{"*each*", "value"}, ref[2:]...), ".")
+			} else if match := eachValueKeyRegex.FindStringSubmatch(ref[1]); match != nil {
+				values[name] = strings.Join(append([]string{"*each*", "value", match[1]}, ref[2:]...), ".")
 			}
 			continue
 		}
+		if ref[0] == "count" && ref[1] == "index" {
+			values[name] = "*each*.key"
+			continue
+		}
 		// "local" variables are not set on the plan
 		// so we ignore them
 		if ref[0] == "local" {
@@ -483,39 +476,55 @@ func (p *Plan) evaluateResourceExpressions(prefix string, forEach map[string]int
 			values[name] = v.Value
 			continue
 		}
+		// The references to the other resources are resolved from their values after the resources are extracted
+		if len(strings.Split(configAddress(refs[0].(string)), ".")) > 2 {
+			values[name] = fmt.Sprintf("*ref*.%s", refs[0])
+			continue
+		}
 		values[name] = fmt.Sprintf("%s", refs[0])
 	}
 	return values, nil
 }
 
-// mapsEqual checks if two maps are equal
-func mapsEqual(resourcesMap1, resourcesMap2 map[string][]Resource) bool {
-	if len(resourcesMap1) != len(resourcesMap2) {
-		return false
-	}
+// eachValueKeyRegex matches the attributes of each.value given by their key like value["size"]
+var eachValueKeyRegex = regexp.MustCompile(`^value\["([^"]+)"\]$`)
 
-	for key, value1 := range resourcesMap1 {
-		if value2, ok := resourcesMap2[key]; ok {
-			if len(value1) != len(value2) {
-				return false
-			}
-			for i, _ := range value1 {
-				map1 := value1[i].Values
-				map2 := value2[i].Values
-				for k, v1 := range map1 {
-					if v2, ok := map2[k]; ok {
-						if !reflect.DeepEqual(v1, v2) {
-							return false
-						}
-					} else {
-						return false
-					}
-				}
-			}
-		} else {
-			return false
+// evaluateForEach returns the evaluated for_each of a resource when it's a constant or a variable, or the configuration
+// address of the resource it iterates over. The variables of the module are used before the variables of the plan.
+func (p *Plan) evaluateForEach(prefix string, forEach map[string]interface{}, variables map[string]Variable) (interface{}, string) {
+	if forEach == nil {
+		return nil, ""
+	}
+	if value, ok := forEach["constant_value"]; ok && value != nil {
+		return value, ""
+	}
+	refs, ok := forEach["references"].([]interface{})
+	if !ok || len(refs) == 0 {
+		return nil, ""
+	}
+	reference, ok := refs[0].(string)
+	if !ok {
+		return nil, ""
+	}
+	ref := strings.Split(reference, ".")
+	if len(ref) < 2 {
+		return nil, ""
+	}
+	switch ref[0] {
+	case "var":
+		if v, ok := variables[ref[1]]; ok && v.Value != nil {
+			return v.Value, ""
 		}
+		if v, ok := p.Variables[ref[1]]; ok && prefix == "" {
+			return v.Value, ""
+		}
+		return nil, ""
+	case "local", "module", "data", "each", "count":
+		return nil, ""
 	}
-
-	return true
+	address := fmt.Sprintf("%s.%s", ref[0], ref[1])
+	if prefix != "" {
+		address = fmt.Sprintf("module.%s.%s", prefix, address)
+	}
+	return nil, address
 }
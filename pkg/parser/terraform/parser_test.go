@@ -0,0 +1,195 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testProvider struct{}
+
+func (testProvider) Name() string { return "aws" }
+
+var testProviderInitializer = ProviderInitializer{
+	MatchNames: []string{"aws"},
+	Provider: func(values map[string]interface{}) (Provider, error) {
+		return testProvider{}, nil
+	},
+}
+
+// readPlan returns the values of the planned resources of the plan fixture in testdata by their address
+// and the diagnostics found while extracting them
+func readPlan(t *testing.T, fixture string) (map[string]map[string]interface{}, []Diagnostic) {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	plan := NewPlan(testProviderInitializer)
+	if err := plan.Read(file); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	resources, err := plan.ExtractPlannedQueries()
+	if err != nil {
+		t.Fatalf("ExtractPlannedQueries() error = %v", err)
+	}
+
+	values := make(map[string]map[string]interface{})
+	for _, res := range resources {
+		values[res.Address] = res.Values
+	}
+	return values, plan.Diagnostics()
+}
+
+// readPlanResources returns the values of the planned resources of the plan fixture in testdata by their address,
+// it fails the test if there are diagnostics or unresolved values
+func readPlanResources(t *testing.T, fixture string) map[string]map[string]interface{} {
+	t.Helper()
+	resources, diagnostics := readPlan(t, fixture)
+	if len(diagnostics) > 0 {
+		t.Errorf("got diagnostics %v, want none", diagnostics)
+	}
+	for address, values := range resources {
+		walkStrings(values, func(s string) {
+			if isUnresolved(s) {
+				t.Errorf("%s has the unresolved value %s", address, s)
+			}
+		})
+	}
+	return resources
+}
+
+func TestPlanExtractPlannedQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		// want are the expected values of some attributes of each resource by its address
+		want map[string]map[string]interface{}
+	}{
+		{
+			name:    "for_each over a map",
+			fixture: "for_each_map.json",
+			want: map[string]map[string]interface{}{
+				`aws_instance.web["a"]`: {"instance_type": "t3.micro", "availability_zone": "us-east-1a", "key_name": "a"},
+				`aws_instance.web["b"]`: {"instance_type": "m5.large", "availability_zone": "us-east-1b", "key_name": "b"},
+			},
+		},
+		{
+			name:    "for_each over a set",
+			fixture: "for_each_set.json",
+			want: map[string]map[string]interface{}{
+				`aws_instance.web["t3.micro"]`: {"instance_type": "t3.micro", "key_name": "t3.micro"},
+				`aws_instance.web["m5.large"]`: {"instance_type": "m5.large", "key_name": "m5.large"},
+			},
+		},
+		{
+			name:    "for_each over another resource",
+			fixture: "for_each_resource.json",
+			want: map[string]map[string]interface{}{
+				`aws_instance.web["a"]`:    {"instance_type": "t3.micro", "availability_zone": "us-east-1a"},
+				`aws_ebs_volume.data["a"]`: {"availability_zone": "us-east-1a"},
+				`aws_ebs_volume.data["b"]`: {"availability_zone": "us-east-1b"},
+			},
+		},
+		{
+			name:    "count.index and a numeric instance key",
+			fixture: "count.json",
+			want: map[string]map[string]interface{}{
+				"aws_network_interface_attachment.nic[0]": {"device_index": 0},
+				"aws_network_interface_attachment.nic[1]": {"device_index": 1},
+				"aws_ebs_volume.data":                     {"availability_zone": "us-east-1b"},
+			},
+		},
+		{
+			name:    "nested modules",
+			fixture: "nested_modules.json",
+			want: map[string]map[string]interface{}{
+				"aws_instance.web":                      {"instance_type": "m5.large", "availability_zone": "us-east-1c"},
+				"module.a.module.b.aws_instance.web":    {"instance_type": "t3.micro", "availability_zone": "us-east-1a"},
+				"module.a.module.b.aws_ebs_volume.data": {"availability_zone": "us-east-1a"},
+			},
+		},
+		{
+			name:    "reference with an explicit key",
+			fixture: "instance_key_reference.json",
+			want: map[string]map[string]interface{}{
+				"aws_ebs_volume.data":    {"availability_zone": "us-east-1b"},
+				"aws_ebs_volume.missing": {"availability_zone": nil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := readPlanResources(t, tt.fixture)
+			for address, want := range tt.want {
+				values, ok := resources[address]
+				if !ok {
+					t.Errorf("resource %s not found", address)
+					continue
+				}
+				for attribute, wantValue := range want {
+					if got := values[attribute]; !reflect.DeepEqual(got, wantValue) {
+						t.Errorf("%s.%s = %#v, want %#v", address, attribute, got, wantValue)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlanExtractPlannedQueriesWithCycles(t *testing.T) {
+	resources, diagnostics := readPlan(t, "reference_cycle.json")
+
+	wantDiagnostics := []Diagnostic{
+		{Address: "aws_instance.a", Message: "reference cycle with aws_instance.b, the values referring to them are unknown"},
+		{Address: "aws_instance.b", Message: "reference cycle with aws_instance.a, the values referring to them are unknown"},
+		{Address: "aws_ebs_volume.cycle", Message: "depends on a reference cycle through aws_instance.a, the values coming from the cycle are unknown"},
+	}
+	if !reflect.DeepEqual(diagnostics, wantDiagnostics) {
+		t.Errorf("Diagnostics() = %v, want %v", diagnostics, wantDiagnostics)
+	}
+
+	// the values from the cycle are unknown while the self-reference and the resources outside of the cycle resolve
+	want := map[string]map[string]interface{}{
+		"aws_instance.a":       {"instance_type": "t3.micro", "availability_zone": nil},
+		"aws_instance.b":       {"instance_type": "m5.large", "availability_zone": nil},
+		"aws_ebs_volume.cycle": {"size": float64(100), "availability_zone": nil},
+		"aws_instance.self":    {"instance_type": "t3.small", "key_name": "t3.small"},
+		"aws_ebs_volume.data":  {"size": float64(50), "availability_zone": "us-east-1c"},
+	}
+	for address, wantValues := range want {
+		values, ok := resources[address]
+		if !ok {
+			t.Errorf("resource %s not found", address)
+			continue
+		}
+		for attribute, wantValue := range wantValues {
+			if got := values[attribute]; !reflect.DeepEqual(got, wantValue) {
+				t.Errorf("%s.%s = %#v, want %#v", address, attribute, got, wantValue)
+			}
+		}
+	}
+}
+
+func TestResourceInstanceKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource Resource
+		want     interface{}
+	}{
+		{name: "count index", resource: Resource{Address: "aws_instance.web[1]", Index: float64(1)}, want: 1},
+		{name: "for_each key", resource: Resource{Address: `aws_instance.web["a"]`, Index: "a"}, want: "a"},
+		{name: "key from the address", resource: Resource{Address: `module.m[0].aws_instance.web["a.b"]`}, want: "a.b"},
+		{name: "single instance", resource: Resource{Address: "module.m[0].aws_instance.web"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resource.InstanceKey(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InstanceKey() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
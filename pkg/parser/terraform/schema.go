@@ -84,6 +84,8 @@ type Resource struct {
 	Name         string                 `json:"name"`
 	ProviderName string                 `json:"provider_name"`
 	Values       map[string]interface{} `json:"values"`
+	// Change is the planned change of the resource from the resource_changes of the plan, nil if it's not there
+	Change *Change `json:"-"`
 }
 
 func (r *Resource) ToResource(region string) schema.ResourceDef {
@@ -94,6 +96,13 @@ func (r *Resource) ToResource(region string) schema.ResourceDef {
 		RegionCode: region,
 		Values:     r.Values,
 	}
+	if r.Change != nil {
+		resourceDef.Change = &schema.ResourceChange{
+			Actions:           r.Change.Actions,
+			UnknownAttributes: r.Change.UnknownAttributes(),
+			ReplacePaths:      r.Change.replacePaths(),
+		}
+	}
 	if strings.Contains(r.ProviderName, "azurerm") {
 		resourceDef.ProviderName = schema.AzureProvider
 	} else {
@@ -109,6 +118,26 @@ type Module struct {
 	ChildModules []*Module  `json:"child_modules"`
 }
 
+// ResourceChange is the planned change of a single resource.
+type ResourceChange struct {
+	Address      string      `json:"address"`
+	Mode         string      `json:"mode"`
+	Type         string      `json:"type"`
+	Name         string      `json:"name"`
+	Index        interface{} `json:"index"`
+	Change       Change      `json:"change"`
+	ActionReason string      `json:"action_reason,omitempty"`
+}
+
+// Change is the change of the values of a resource with the actions to apply it.
+type Change struct {
+	Actions []string `json:"actions"`
+	// AfterUnknown mirrors the after values with true for the values that are known only after apply
+	AfterUnknown interface{} `json:"after_unknown"`
+	// ReplacePaths are the paths of the attributes forcing the replacement, each one is a list of keys and indexes
+	ReplacePaths [][]interface{} `json:"replace_paths"`
+}
+
 // Configuration is a Terraform plan configuration.
 type Configuration struct {
 	ProviderConfig map[string]ProviderConfig `json:"provider_config"`
@@ -0,0 +1,136 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/usage"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rawState is the format of the state files written by terraform like terraform.tfstate
+type rawState struct {
+	Version   int                `json:"version"`
+	Resources []rawStateResource `json:"resources"`
+}
+
+type rawStateResource struct {
+	Module    string `json:"module"`
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	Instances []struct {
+		IndexKey   interface{}            `json:"index_key"`
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"instances"`
+}
+
+// ReadState reads a state file, either the state file written by terraform or the output of `terraform show -json`
+func ReadState(r io.Reader) (*State, error) {
+	var s struct {
+		Values *Values `json:"values"`
+		rawState
+	}
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, err
+	}
+	if s.Values != nil {
+		return &State{Values: *s.Values}, nil
+	}
+	if s.Version == 0 {
+		return nil, fmt.Errorf("unsupported state format, it's neither a terraform state file nor the output of show -json")
+	}
+	return &State{Values: s.rawState.values()}, nil
+}
+
+// values converts the resources of the state file to the module tree of `terraform show -json`
+func (s rawState) values() Values {
+	modules := map[string]*Module{"": {}}
+	var ensureModule func(address string) *Module
+	ensureModule = func(address string) *Module {
+		if module, ok := modules[address]; ok {
+			return module
+		}
+		module := &Module{Address: address}
+		modules[address] = module
+		parent := ""
+		if i := strings.LastIndex(address, ".module."); i >= 0 {
+			parent = address[:i]
+		}
+		parentModule := ensureModule(parent)
+		parentModule.ChildModules = append(parentModule.ChildModules, module)
+		return module
+	}
+
+	for _, res := range s.Resources {
+		module := ensureModule(res.Module)
+		address := fmt.Sprintf("%s.%s", res.Type, res.Name)
+		if res.Mode == "data" {
+			address = "data." + address
+		}
+		if res.Module != "" {
+			address = fmt.Sprintf("%s.%s", res.Module, address)
+		}
+		for _, instance := range res.Instances {
+			instanceAddress := address
+			switch key := instance.IndexKey.(type) {
+			case float64:
+				instanceAddress = fmt.Sprintf("%s[%d]", address, int(key))
+			case string:
+				instanceAddress = fmt.Sprintf("%s[%s]", address, strconv.Quote(key))
+			}
+			module.Resources = append(module.Resources, Resource{
+				Address:      instanceAddress,
+				Index:        instance.IndexKey,
+				Mode:         res.Mode,
+				Type:         res.Type,
+				Name:         res.Name,
+				ProviderName: stateProviderName(res.Provider),
+				Values:       instance.Attributes,
+			})
+		}
+	}
+	for _, module := range modules {
+		sort.Slice(module.ChildModules, func(i, j int) bool {
+			return module.ChildModules[i].Address < module.ChildModules[j].Address
+		})
+	}
+	return Values{RootModule: *modules[""]}
+}
+
+// stateProviderName returns the provider source address of a provider of the state file
+// like provider["registry.terraform.io/hashicorp/aws"]
+func stateProviderName(provider string) string {
+	provider = strings.TrimPrefix(provider, "provider[")
+	provider = strings.TrimSuffix(provider, "]")
+	if name, err := strconv.Unquote(provider); err == nil {
+		return name
+	}
+	return provider
+}
+
+// ExtractQueries returns the managed resources of the state with the usage set on their values
+func (s *State) ExtractQueries(u usage.Usage) []Resource {
+	return moduleStateResources(&s.Values.RootModule, u)
+}
+
+func moduleStateResources(module *Module, u usage.Usage) []Resource {
+	var resources []Resource
+	for _, res := range module.Resources {
+		if res.Mode != "" && res.Mode != "managed" {
+			continue
+		}
+		if res.Values == nil {
+			res.Values = make(map[string]interface{})
+		}
+		res.Values[usage.Key] = u.GetUsage(res.Type, res.Address)
+		resources = append(resources, res)
+	}
+	for _, child := range module.ChildModules {
+		resources = append(resources, moduleStateResources(child, u)...)
+	}
+	return resources
+}
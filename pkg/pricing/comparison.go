@@ -0,0 +1,230 @@
+package pricing
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+var (
+	bold      = color.New(color.Bold)
+	underline = color.New(color.Underline)
+)
+
+// Module returns the module with only the resources having the purchase option, priced with the option,
+// and the number of those resources
+func Module(module schema.ModuleDef, name string) (schema.ModuleDef, int) {
+	return module.MapResources(applier(name))
+}
+
+// Resources returns the resources having the purchase option, priced with the option
+func Resources(resources []schema.ResourceDef, name string) []schema.ResourceDef {
+	return schema.MapResources(resources, applier(name))
+}
+
+// applier returns the function pricing the resources having the purchase option with it
+func applier(name string) func(schema.ResourceDef) (schema.ResourceDef, bool) {
+	return func(res schema.ResourceDef) (schema.ResourceDef, bool) {
+		o, ok := option(res.Type, name)
+		if !ok {
+			return res, false
+		}
+		return o.Apply(res), true
+	}
+}
+
+// ResourceComparison is the cost of a compute resource as it's configured and priced with each of its purchase options
+type ResourceComparison struct {
+	Address string
+	Type    string
+	Current decimal.Decimal
+	// Costs are keyed by the purchase option, the options the resource couldn't be priced with are left out
+	Costs map[string]decimal.Decimal
+}
+
+// Cheapest returns the purchase option with the lowest cost, false if the resource couldn't be priced with any option
+func (r ResourceComparison) Cheapest() (string, decimal.Decimal, bool) {
+	var cheapest string
+	var cheapestCost decimal.Decimal
+	for _, name := range OptionNames {
+		c, ok := r.Costs[name]
+		if !ok {
+			continue
+		}
+		if cheapest == "" || c.LessThan(cheapestCost) {
+			cheapest, cheapestCost = name, c
+		}
+	}
+	return cheapest, cheapestCost, cheapest != ""
+}
+
+// Unavailable returns the purchase options the resource type has no pricing for, in the order they're shown
+func (r ResourceComparison) Unavailable() []string {
+	var names []string
+	for _, name := range OptionNames {
+		if !HasOption(r.Type, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Savings returns how much is saved by using the cheapest purchase option instead of the current one
+func (r ResourceComparison) Savings() decimal.Decimal {
+	_, cheapestCost, ok := r.Cheapest()
+	if !ok || cheapestCost.GreaterThanOrEqual(r.Current) {
+		return decimal.Zero
+	}
+	return r.Current.Sub(cheapestCost)
+}
+
+// Comparison is the comparison of the costs of the compute resources with their purchase options for the period
+type Comparison struct {
+	Period    cost.Period
+	Currency  string
+	Resources []ResourceComparison
+}
+
+// Compare compares the cost of the compute resources in the state with their cost in the priced states,
+// keyed by the purchase option. The resources are matched by their address and sorted by their savings.
+func Compare(state *cost.ModularState, priced map[string]*cost.ModularState, p cost.Period) (Comparison, error) {
+	comparison := Comparison{Period: p}
+	pricedResources := make(map[string]map[string]cost.Resource)
+	for name, s := range priced {
+		if s != nil {
+			pricedResources[name] = s.ResourcesByAddress()
+		}
+	}
+
+	for address, res := range state.ResourcesByAddress() {
+		if !IsComparable(res.Type) {
+			continue
+		}
+		current, err := res.CostForPeriod(p)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("failed to get cost of resource %s: %w", address, err)
+		}
+		if comparison.Currency == "" {
+			comparison.Currency = current.Currency
+		}
+		rc := ResourceComparison{
+			Address: address,
+			Type:    res.Type,
+			Current: current.Decimal,
+			Costs:   make(map[string]decimal.Decimal),
+		}
+		for name, resources := range pricedResources {
+			pricedRes, ok := resources[address]
+			if !ok || len(pricedRes.UnpricedComponents(address)) > 0 {
+				continue
+			}
+			c, err := pricedRes.CostForPeriod(p)
+			if err != nil {
+				return Comparison{}, fmt.Errorf("failed to get %s cost of resource %s: %w", name, address, err)
+			}
+			rc.Costs[name] = c.Decimal
+		}
+		comparison.Resources = append(comparison.Resources, rc)
+	}
+	sort.Slice(comparison.Resources, func(i, j int) bool {
+		savingsI, savingsJ := comparison.Resources[i].Savings(), comparison.Resources[j].Savings()
+		if !savingsI.Equal(savingsJ) {
+			return savingsI.GreaterThan(savingsJ)
+		}
+		return comparison.Resources[i].Address < comparison.Resources[j].Address
+	})
+	return comparison, nil
+}
+
+// Current returns the total cost of the compared resources as they're configured
+func (c Comparison) Current() decimal.Decimal {
+	var total decimal.Decimal
+	for _, r := range c.Resources {
+		total = total.Add(r.Current)
+	}
+	return total
+}
+
+// Savings returns the total savings of using the cheapest purchase option of every compared resource
+func (c Comparison) Savings() decimal.Decimal {
+	var total decimal.Decimal
+	for _, r := range c.Resources {
+		total = total.Add(r.Savings())
+	}
+	return total
+}
+
+// Notes returns why the compared resources miss some of the purchase options, sorted and without duplicates
+func (c Comparison) Notes() []string {
+	seen := make(map[string]bool)
+	var notes []string
+	for _, r := range c.Resources {
+		note, ok := unavailableNotes[r.Type]
+		if !ok || seen[note] {
+			continue
+		}
+		seen[note] = true
+		notes = append(notes, note)
+	}
+	sort.Strings(notes)
+	return notes
+}
+
+// String returns a string to show the cost of each compute resource with each of the purchase options and the savings
+func (c Comparison) String() string {
+	if len(c.Resources) == 0 {
+		return bold.Sprint("Pricing comparison") + "\nNo compute resources to compare the purchase options of"
+	}
+	ac := cost.MoneyFormatter(c.Currency, c.Period.Precision())
+
+	t := table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.Style().Options.SeparateRows = false
+	t.Style().Options.SeparateHeader = false
+	t.Style().Format.Header = text.FormatDefault
+
+	headers := table.Row{underline.Sprint("Resource"), underline.Sprint("Current")}
+	columns := []table.ColumnConfig{{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignLeft}}
+	for i, name := range OptionNames {
+		headers = append(headers, underline.Sprint(name))
+		columns = append(columns, table.ColumnConfig{Number: i + 3, Align: text.AlignRight, AlignHeader: text.AlignRight})
+	}
+	headers = append(headers, underline.Sprint("Cheapest"), underline.Sprint("Savings"))
+	columns = append(columns,
+		table.ColumnConfig{Number: 2, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		table.ColumnConfig{Number: len(OptionNames) + 4, Align: text.AlignRight, AlignHeader: text.AlignRight})
+	t.AppendHeader(headers)
+	t.SetColumnConfigs(columns)
+
+	for _, r := range c.Resources {
+		row := table.Row{r.Address, ac.FormatMoney(r.Current)}
+		for _, name := range OptionNames {
+			if optionCost, ok := r.Costs[name]; ok {
+				row = append(row, ac.FormatMoney(optionCost))
+			} else if !HasOption(r.Type, name) {
+				row = append(row, "n/a")
+			} else {
+				row = append(row, "-")
+			}
+		}
+		cheapest, _, ok := r.Cheapest()
+		if !ok {
+			cheapest = "-"
+		}
+		row = append(row, cheapest, ac.FormatMoney(r.Savings()))
+		t.AppendRow(row)
+	}
+
+	out := fmt.Sprintf("%s\n%s\n%s:    %s of %s", bold.Sprintf("Pricing comparison (%s)", c.Period.Title()), t.Render(),
+		bold.Sprint("Potential savings"), ac.FormatMoney(c.Savings()), ac.FormatMoney(c.Current()))
+	for _, note := range c.Notes() {
+		out += "\nn/a: " + note
+	}
+	return out
+}
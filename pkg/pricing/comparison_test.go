@@ -0,0 +1,73 @@
+package pricing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/shopspring/decimal"
+)
+
+func TestResourceComparisonUnavailable(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		want         []string
+	}{
+		{resourceType: "aws_instance"},
+		{resourceType: "aws_eks_node_group"},
+		{resourceType: "azurerm_linux_virtual_machine", want: []string{Reserved1Year, Reserved3Year}},
+		{resourceType: "azurerm_windows_virtual_machine_scale_set", want: []string{Reserved1Year, Reserved3Year}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			if got := (ResourceComparison{Type: tt.resourceType}).Unavailable(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparisonString(t *testing.T) {
+	dec := func(f float64) decimal.Decimal { return decimal.NewFromFloat(f) }
+	tests := []struct {
+		name      string
+		resources []ResourceComparison
+		wantNotes []string
+		wantNA    bool
+	}{
+		{
+			name: "aws",
+			resources: []ResourceComparison{{Address: "aws_instance.web", Type: "aws_instance", Current: dec(70),
+				Costs: map[string]decimal.Decimal{OnDemand: dec(70), Reserved1Year: dec(44), Reserved3Year: dec(30), Spot: dec(21)}}},
+		},
+		{
+			name: "azure",
+			resources: []ResourceComparison{
+				{Address: "azurerm_linux_virtual_machine.a", Type: "azurerm_linux_virtual_machine", Current: dec(70),
+					Costs: map[string]decimal.Decimal{OnDemand: dec(70), Spot: dec(14)}},
+				{Address: "azurerm_windows_virtual_machine.b", Type: "azurerm_windows_virtual_machine", Current: dec(90),
+					Costs: map[string]decimal.Decimal{OnDemand: dec(90)}},
+			},
+			wantNotes: []string{azureReservedNote},
+			wantNA:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Comparison{Period: cost.Monthly, Currency: "USD", Resources: tt.resources}
+			if got := c.Notes(); !reflect.DeepEqual(got, tt.wantNotes) {
+				t.Errorf("Notes() = %v, want %v", got, tt.wantNotes)
+			}
+			out := c.String()
+			if got := strings.Contains(out, "n/a"); got != tt.wantNA {
+				t.Errorf("String() shows n/a = %t, want %t:\n%s", got, tt.wantNA, out)
+			}
+			for _, note := range tt.wantNotes {
+				if !strings.Contains(out, "n/a: "+note) {
+					t.Errorf("String() = %s, want the note %q", out, note)
+				}
+			}
+		})
+	}
+}
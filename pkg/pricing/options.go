@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	usagePackage "github.com/kaytu-io/pennywise/pkg/usage"
+)
+
+// Names of the purchase options the compute resources are compared with
+const (
+	OnDemand      = "on-demand"
+	Reserved1Year = "reserved-1yr"
+	Reserved3Year = "reserved-3yr"
+	Spot          = "spot"
+)
+
+// OptionNames are the names of the purchase options in the order they're shown
+var OptionNames = []string{OnDemand, Reserved1Year, Reserved3Year, Spot}
+
+// Option is a purchase option the resources are priced with by setting their usage keys and attributes
+type Option struct {
+	Name string
+	// Usage is set on the usage of the resource, the keys with nil values are removed
+	Usage map[string]interface{}
+	// Values is set on the attributes of the resource, the keys with nil values are removed
+	Values map[string]interface{}
+}
+
+var awsReservedKeys = []string{"reserved_instance_type", "reserved_instance_term", "reserved_instance_payment_option"}
+
+func awsReserved(term string) map[string]interface{} {
+	return map[string]interface{}{
+		"reserved_instance_type":           "standard",
+		"reserved_instance_term":           term,
+		"reserved_instance_payment_option": "no_upfront",
+	}
+}
+
+func awsOnDemand() map[string]interface{} {
+	usage := make(map[string]interface{})
+	for _, key := range awsReservedKeys {
+		usage[key] = nil
+	}
+	return usage
+}
+
+// Options are the purchase options of each compute resource type
+var Options = map[string][]Option{
+	"aws_instance": {
+		{Name: OnDemand, Usage: awsOnDemand(), Values: map[string]interface{}{"instance_market_options": nil}},
+		{Name: Reserved1Year, Usage: awsReserved("1_year"), Values: map[string]interface{}{"instance_market_options": nil}},
+		{Name: Reserved3Year, Usage: awsReserved("3_year"), Values: map[string]interface{}{"instance_market_options": nil}},
+		{Name: Spot, Usage: awsOnDemand(), Values: map[string]interface{}{
+			"instance_market_options": []interface{}{map[string]interface{}{"market_type": "spot"}},
+		}},
+	},
+	"aws_eks_node_group": {
+		{Name: OnDemand, Usage: awsOnDemand(), Values: map[string]interface{}{"capacity_type": "ON_DEMAND"}},
+		{Name: Reserved1Year, Usage: awsReserved("1_year"), Values: map[string]interface{}{"capacity_type": "ON_DEMAND"}},
+		{Name: Reserved3Year, Usage: awsReserved("3_year"), Values: map[string]interface{}{"capacity_type": "ON_DEMAND"}},
+		{Name: Spot, Usage: awsOnDemand(), Values: map[string]interface{}{"capacity_type": "SPOT"}},
+	},
+	"azurerm_linux_virtual_machine":             azureOptions,
+	"azurerm_windows_virtual_machine":           azureOptions,
+	"azurerm_linux_virtual_machine_scale_set":   azureOptions,
+	"azurerm_windows_virtual_machine_scale_set": azureOptions,
+}
+
+// azureOptions have no reserved options, the pricing has no usage keys for the Azure reservations
+var azureOptions = []Option{
+	{Name: OnDemand, Values: map[string]interface{}{"priority": "Regular"}},
+	{Name: Spot, Values: map[string]interface{}{"priority": "Spot"}},
+}
+
+const azureReservedNote = "reserved pricing isn't available for Azure virtual machines"
+
+// unavailableNotes explain why the compute resource types miss some of the purchase options
+var unavailableNotes = map[string]string{
+	"azurerm_linux_virtual_machine":             azureReservedNote,
+	"azurerm_windows_virtual_machine":           azureReservedNote,
+	"azurerm_linux_virtual_machine_scale_set":   azureReservedNote,
+	"azurerm_windows_virtual_machine_scale_set": azureReservedNote,
+}
+
+// IsComparable checks if the resource type has purchase options to compare
+func IsComparable(resourceType string) bool {
+	_, ok := Options[resourceType]
+	return ok
+}
+
+// option returns the purchase option of the resource type by its name
+func option(resourceType, name string) (Option, bool) {
+	for _, o := range Options[resourceType] {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// HasOption checks if the resource type can be priced with the purchase option
+func HasOption(resourceType, name string) bool {
+	_, ok := option(resourceType, name)
+	return ok
+}
+
+// Apply returns a copy of the resource with the usage and the attributes of the option
+func (o Option) Apply(res schema.ResourceDef) schema.ResourceDef {
+	values := make(map[string]interface{}, len(res.Values))
+	for k, v := range res.Values {
+		values[k] = v
+	}
+	setValues(values, o.Values)
+
+	usage := make(map[string]interface{})
+	if current, ok := values[usagePackage.Key].(map[string]interface{}); ok {
+		for k, v := range current {
+			usage[k] = v
+		}
+	}
+	setValues(usage, o.Usage)
+	values[usagePackage.Key] = usage
+
+	res.Values = values
+	return res
+}
+
+func setValues(values, overrides map[string]interface{}) {
+	for k, v := range overrides {
+		if v == nil {
+			delete(values, k)
+			continue
+		}
+		values[k] = v
+	}
+}
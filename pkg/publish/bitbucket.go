@@ -0,0 +1,97 @@
+package publish
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// BitbucketPublisher publishes the comment on a Bitbucket Cloud pull request
+type BitbucketPublisher struct {
+	APIURL      string
+	Token       string
+	Repository  string
+	PullRequest string
+}
+
+type bitbucketContent struct {
+	Raw string `json:"raw"`
+}
+
+type bitbucketComment struct {
+	ID      int64            `json:"id"`
+	Content bitbucketContent `json:"content"`
+}
+
+type bitbucketComments struct {
+	Values []bitbucketComment `json:"values"`
+	Next   string             `json:"next"`
+}
+
+// NewBitbucketPublisherFromEnv returns a BitbucketPublisher configured by the Bitbucket Pipelines environment variables.
+func NewBitbucketPublisherFromEnv() (*BitbucketPublisher, error) {
+	token, err := requiredEnv("PENNYWISE_BITBUCKET_TOKEN", "BITBUCKET_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	repository, err := requiredEnv("BITBUCKET_REPO_FULL_NAME")
+	if err != nil {
+		return nil, err
+	}
+	pullRequest, err := requiredEnv("PENNYWISE_PULL_REQUEST", "BITBUCKET_PR_ID")
+	if err != nil {
+		return nil, err
+	}
+	return &BitbucketPublisher{
+		APIURL:      strings.TrimSuffix(envOrDefault("BITBUCKET_API_URL", "https://api.bitbucket.org/2.0"), "/"),
+		Token:       token,
+		Repository:  repository,
+		PullRequest: pullRequest,
+	}, nil
+}
+
+func (p *BitbucketPublisher) Name() string { return Bitbucket }
+
+// MaxLength returns the maximum length of a Bitbucket comment
+func (p *BitbucketPublisher) MaxLength() int { return 32768 }
+
+func (p *BitbucketPublisher) Publish(body string) error {
+	comment, err := p.findComment()
+	if err != nil {
+		return err
+	}
+	payload := map[string]bitbucketContent{"content": {Raw: body}}
+	if comment != nil {
+		return doRequest(http.MethodPut, fmt.Sprintf("%s/%d", p.commentsURL(), comment.ID), p.headers(), payload, nil)
+	}
+	return doRequest(http.MethodPost, p.commentsURL(), p.headers(), payload, nil)
+}
+
+// findComment returns the comment published by a previous run, nil if there isn't any
+func (p *BitbucketPublisher) findComment() (*bitbucketComment, error) {
+	next := p.commentsURL() + "?pagelen=100"
+	for next != "" {
+		var comments bitbucketComments
+		err := doRequest(http.MethodGet, next, p.headers(), nil, &comments)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments.Values {
+			if strings.Contains(c.Content.Raw, Marker) {
+				return &c, nil
+			}
+		}
+		next = comments.Next
+	}
+	return nil, nil
+}
+
+func (p *BitbucketPublisher) commentsURL() string {
+	return fmt.Sprintf("%s/repositories/%s/pullrequests/%s/comments", p.APIURL, p.Repository, p.PullRequest)
+}
+
+func (p *BitbucketPublisher) headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + p.Token,
+	}
+}
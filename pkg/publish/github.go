@@ -0,0 +1,98 @@
+package publish
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GitHubPublisher publishes the comment on a GitHub pull request
+type GitHubPublisher struct {
+	APIURL      string
+	Token       string
+	Repository  string
+	PullRequest string
+}
+
+type githubComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+var githubPullRefRegex = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// NewGitHubPublisherFromEnv returns a GitHubPublisher configured by the GitHub Actions environment variables.
+// The pull request number is read from PENNYWISE_PULL_REQUEST, or GITHUB_REF on pull request events.
+func NewGitHubPublisherFromEnv() (*GitHubPublisher, error) {
+	token, err := requiredEnv("PENNYWISE_GITHUB_TOKEN", "GITHUB_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	repository, err := requiredEnv("GITHUB_REPOSITORY")
+	if err != nil {
+		return nil, err
+	}
+	pullRequest := os.Getenv("PENNYWISE_PULL_REQUEST")
+	if pullRequest == "" {
+		match := githubPullRefRegex.FindStringSubmatch(os.Getenv("GITHUB_REF"))
+		if match == nil {
+			return nil, fmt.Errorf("pull request number not found, set PENNYWISE_PULL_REQUEST or run on a pull request event")
+		}
+		pullRequest = match[1]
+	}
+	return &GitHubPublisher{
+		APIURL:      strings.TrimSuffix(envOrDefault("GITHUB_API_URL", "https://api.github.com"), "/"),
+		Token:       token,
+		Repository:  repository,
+		PullRequest: pullRequest,
+	}, nil
+}
+
+func (p *GitHubPublisher) Name() string { return GitHub }
+
+// MaxLength returns the maximum length of a GitHub comment
+func (p *GitHubPublisher) MaxLength() int { return 65536 }
+
+func (p *GitHubPublisher) Publish(body string) error {
+	comment, err := p.findComment()
+	if err != nil {
+		return err
+	}
+	payload := map[string]string{"body": body}
+	if comment != nil {
+		url := fmt.Sprintf("%s/repos/%s/issues/comments/%d", p.APIURL, p.Repository, comment.ID)
+		return doRequest(http.MethodPatch, url, p.headers(), payload, nil)
+	}
+	url := fmt.Sprintf("%s/repos/%s/issues/%s/comments", p.APIURL, p.Repository, p.PullRequest)
+	return doRequest(http.MethodPost, url, p.headers(), payload, nil)
+}
+
+// findComment returns the comment published by a previous run, nil if there isn't any
+func (p *GitHubPublisher) findComment() (*githubComment, error) {
+	const perPage = 100
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/issues/%s/comments?per_page=%d&page=%d", p.APIURL, p.Repository, p.PullRequest, perPage, page)
+		var comments []githubComment
+		err := doRequest(http.MethodGet, url, p.headers(), nil, &comments)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			if strings.Contains(c.Body, Marker) {
+				return &c, nil
+			}
+		}
+		if len(comments) < perPage {
+			return nil, nil
+		}
+	}
+}
+
+func (p *GitHubPublisher) headers() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + p.Token,
+		"Accept":        "application/vnd.github+json",
+	}
+}
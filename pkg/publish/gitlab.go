@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLabPublisher publishes the comment (note) on a GitLab merge request
+type GitLabPublisher struct {
+	APIURL       string
+	Token        string
+	ProjectID    string
+	MergeRequest string
+}
+
+type gitlabNote struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// NewGitLabPublisherFromEnv returns a GitLabPublisher configured by the GitLab CI environment variables.
+// Self-hosted instances are supported through CI_API_V4_URL.
+func NewGitLabPublisherFromEnv() (*GitLabPublisher, error) {
+	token, err := requiredEnv("PENNYWISE_GITLAB_TOKEN", "GITLAB_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := requiredEnv("CI_PROJECT_ID")
+	if err != nil {
+		return nil, err
+	}
+	mergeRequest, err := requiredEnv("PENNYWISE_PULL_REQUEST", "CI_MERGE_REQUEST_IID")
+	if err != nil {
+		return nil, err
+	}
+	return &GitLabPublisher{
+		APIURL:       strings.TrimSuffix(envOrDefault("CI_API_V4_URL", "https://gitlab.com/api/v4"), "/"),
+		Token:        token,
+		ProjectID:    projectID,
+		MergeRequest: mergeRequest,
+	}, nil
+}
+
+func (p *GitLabPublisher) Name() string { return GitLab }
+
+// MaxLength returns the maximum length of a GitLab note
+func (p *GitLabPublisher) MaxLength() int { return 1000000 }
+
+func (p *GitLabPublisher) Publish(body string) error {
+	note, err := p.findNote()
+	if err != nil {
+		return err
+	}
+	payload := map[string]string{"body": body}
+	if note != nil {
+		return doRequest(http.MethodPut, fmt.Sprintf("%s/%d", p.notesURL(), note.ID), p.headers(), payload, nil)
+	}
+	return doRequest(http.MethodPost, p.notesURL(), p.headers(), payload, nil)
+}
+
+// findNote returns the note published by a previous run, nil if there isn't any
+func (p *GitLabPublisher) findNote() (*gitlabNote, error) {
+	const perPage = 100
+	for page := 1; ; page++ {
+		var notes []gitlabNote
+		err := doRequest(http.MethodGet, fmt.Sprintf("%s?per_page=%d&page=%d", p.notesURL(), perPage, page), p.headers(), nil, &notes)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if strings.Contains(n.Body, Marker) {
+				return &n, nil
+			}
+		}
+		if len(notes) < perPage {
+			return nil, nil
+		}
+	}
+}
+
+func (p *GitLabPublisher) notesURL() string {
+	return fmt.Sprintf("%s/projects/%s/merge_requests/%s/notes", p.APIURL, url.PathEscape(p.ProjectID), p.MergeRequest)
+}
+
+func (p *GitLabPublisher) headers() map[string]string {
+	return map[string]string{
+		"PRIVATE-TOKEN": p.Token,
+	}
+}
@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Marker is a hidden comment added to the published comments,
+// it's used to find the comment on the next runs and update it instead of adding a new one.
+const Marker = "<!-- pennywise-cost-diff -->"
+
+// Publisher posts the diff to a code review system as a single comment on the pull request
+type Publisher interface {
+	// Name returns the name of the code review system
+	Name() string
+	// MaxLength returns the maximum length of a comment body
+	MaxLength() int
+	// Publish creates the comment with the body, or updates it if it's already created by a previous run
+	Publish(body string) error
+}
+
+// Names of the supported publishers
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+)
+
+// NewPublisher returns the publisher with the name configured by the environment variables of the CI
+func NewPublisher(name string) (Publisher, error) {
+	switch name {
+	case GitHub:
+		return NewGitHubPublisherFromEnv()
+	case GitLab:
+		return NewGitLabPublisherFromEnv()
+	case Bitbucket:
+		return NewBitbucketPublisherFromEnv()
+	default:
+		return nil, fmt.Errorf("unsupported publisher %s, supported publishers are %s, %s and %s", name, GitHub, GitLab, Bitbucket)
+	}
+}
+
+// CommentBody returns the body of the comment with the marker
+func CommentBody(content string) string {
+	return Marker + "\n" + content
+}
+
+// requiredEnv returns the value of the first defined environment variable of the names
+func requiredEnv(names ...string) (string, error) {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("environment variable %s is not set", strings.Join(names, " or "))
+}
+
+// envOrDefault returns the value of the environment variable or the default value if it's not set
+func envOrDefault(name, defaultValue string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+// doRequest sends the json payload to the code review system API and decodes the response in v
+func doRequest(method, url string, headers map[string]string, payload interface{}, v interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, val := range headers {
+		req.Header.Set(k, val)
+	}
+
+	client := http.Client{Timeout: time.Minute}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		d, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("%s %s: http status: %d: %s", method, url, res.StatusCode, d)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,380 @@
+package publish
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// apiRequest is a request received by the fake code review system API
+type apiRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// fakeAPI records the requests and answers them with its handler
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []apiRequest
+}
+
+// serve starts the fake API server, the handler returns the response of each request, nil for an empty one
+func (a *fakeAPI) serve(t *testing.T, handler func(r apiRequest) (int, interface{})) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := apiRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header}
+		if r.Body != nil && r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+				t.Errorf("decode %s %s body: %v", r.Method, r.URL, err)
+			}
+		}
+		a.mu.Lock()
+		a.requests = append(a.requests, req)
+		a.mu.Unlock()
+
+		status, response := handler(req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if response != nil {
+			json.NewEncoder(w).Encode(response)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// writes returns the requests changing the comments
+func (a *fakeAPI) writes() []apiRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var writes []apiRequest
+	for _, r := range a.requests {
+		if r.Method != http.MethodGet {
+			writes = append(writes, r)
+		}
+	}
+	return writes
+}
+
+// pages returns the number of the comment pages requested
+func (a *fakeAPI) pages() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var pages int
+	for _, r := range a.requests {
+		if r.Method == http.MethodGet {
+			pages++
+		}
+	}
+	return pages
+}
+
+// page returns the items of the 1-based page of the given size
+func page[T any](items []T, number, size int) []T {
+	start := (number - 1) * size
+	if start < 0 || start >= len(items) {
+		return []T{}
+	}
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// queryInt returns the integer value of the query parameter, the default value if it's not set or invalid
+func queryInt(query url.Values, name string, defaultValue int) int {
+	n, err := strconv.Atoi(query.Get(name))
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// testComment is a comment of the fake API, converted to the comment type of each publisher
+type testComment struct {
+	ID   int64
+	Body string
+}
+
+// testComments returns count comments without the marker
+func testComments(count int) []testComment {
+	var comments []testComment
+	for i := 0; i < count; i++ {
+		comments = append(comments, testComment{ID: int64(i + 1), Body: fmt.Sprintf("comment %d", i+1)})
+	}
+	return comments
+}
+
+// publisherTest defines the API of a publisher for the tests shared by the publishers
+type publisherTest struct {
+	name string
+	// env returns the environment variables configuring the publisher with the API URL and the token
+	env func(apiURL, token string) map[string]string
+	// pullRequestEnv are the environment variables the pull request is read from
+	pullRequestEnv []string
+	// authHeader is the header with the token, formatted by authValue
+	authHeader, authValue string
+	// pageSizeParam is the query parameter of the page size the publisher requests
+	pageSizeParam string
+	pageSize      int
+	// linkedPages is set if the next page is requested by the URL in the response instead of until an empty page
+	linkedPages bool
+	// commentsPath is the path of the comments listed and created, updatePath is the one the updated comments are under
+	commentsPath, updatePath string
+	updateMethod             string
+	// response returns the payload of the page of comments, next is the URL of the next page if there is one
+	response func(comments []testComment, next string) interface{}
+	// body returns the comment body of the payload of a write
+	body func(r apiRequest) interface{}
+}
+
+var publisherTests = []publisherTest{
+	{
+		name: GitHub,
+		env: func(apiURL, token string) map[string]string {
+			return map[string]string{
+				"PENNYWISE_GITHUB_TOKEN": token,
+				"GITHUB_REPOSITORY":      "owner/repo",
+				"GITHUB_REF":             "refs/pull/7/merge",
+				"GITHUB_API_URL":         apiURL + "/",
+			}
+		},
+		pullRequestEnv: []string{"PENNYWISE_PULL_REQUEST", "GITHUB_REF"},
+		authHeader:     "Authorization",
+		authValue:      "Bearer %s",
+		pageSizeParam:  "per_page",
+		pageSize:       100,
+		commentsPath:   "/repos/owner/repo/issues/7/comments",
+		updatePath:     "/repos/owner/repo/issues/comments",
+		updateMethod:   http.MethodPatch,
+		response: func(comments []testComment, _ string) interface{} {
+			page := []githubComment{}
+			for _, c := range comments {
+				page = append(page, githubComment{ID: c.ID, Body: c.Body})
+			}
+			return page
+		},
+		body: func(r apiRequest) interface{} { return r.Body["body"] },
+	},
+	{
+		name: GitLab,
+		env: func(apiURL, token string) map[string]string {
+			return map[string]string{
+				"PENNYWISE_GITLAB_TOKEN": token,
+				"CI_PROJECT_ID":          "group/project",
+				"CI_MERGE_REQUEST_IID":   "7",
+				"CI_API_V4_URL":          apiURL + "/",
+			}
+		},
+		pullRequestEnv: []string{"PENNYWISE_PULL_REQUEST", "CI_MERGE_REQUEST_IID"},
+		authHeader:     "PRIVATE-TOKEN",
+		authValue:      "%s",
+		pageSizeParam:  "per_page",
+		pageSize:       100,
+		commentsPath:   "/projects/group/project/merge_requests/7/notes",
+		updatePath:     "/projects/group/project/merge_requests/7/notes",
+		updateMethod:   http.MethodPut,
+		response: func(comments []testComment, _ string) interface{} {
+			page := []gitlabNote{}
+			for _, c := range comments {
+				page = append(page, gitlabNote{ID: c.ID, Body: c.Body})
+			}
+			return page
+		},
+		body: func(r apiRequest) interface{} { return r.Body["body"] },
+	},
+	{
+		name: Bitbucket,
+		env: func(apiURL, token string) map[string]string {
+			return map[string]string{
+				"PENNYWISE_BITBUCKET_TOKEN": token,
+				"BITBUCKET_REPO_FULL_NAME":  "workspace/repo",
+				"BITBUCKET_PR_ID":           "7",
+				"BITBUCKET_API_URL":         apiURL + "/",
+			}
+		},
+		pullRequestEnv: []string{"PENNYWISE_PULL_REQUEST", "BITBUCKET_PR_ID"},
+		authHeader:     "Authorization",
+		authValue:      "Bearer %s",
+		pageSizeParam:  "pagelen",
+		pageSize:       100,
+		linkedPages:    true,
+		commentsPath:   "/repositories/workspace/repo/pullrequests/7/comments",
+		updatePath:     "/repositories/workspace/repo/pullrequests/7/comments",
+		updateMethod:   http.MethodPut,
+		response: func(comments []testComment, next string) interface{} {
+			page := bitbucketComments{Values: []bitbucketComment{}, Next: next}
+			for _, c := range comments {
+				page.Values = append(page.Values, bitbucketComment{ID: c.ID, Content: bitbucketContent{Raw: c.Body}})
+			}
+			return page
+		},
+		body: func(r apiRequest) interface{} {
+			content, _ := r.Body["content"].(map[string]interface{})
+			return content["raw"]
+		},
+	},
+}
+
+// serve starts the fake API of the publisher serving the comments of the pull request 7 to the token
+func (pt publisherTest) serve(t *testing.T, api *fakeAPI, comments []testComment) string {
+	var apiURL string
+	apiURL = api.serve(t, func(r apiRequest) (int, interface{}) {
+		if r.Header.Get(pt.authHeader) != fmt.Sprintf(pt.authValue, "token") {
+			return http.StatusUnauthorized, nil
+		}
+		switch {
+		case r.Method == http.MethodGet && r.Path == pt.commentsPath:
+			number, size := queryInt(r.Query, "page", 1), queryInt(r.Query, pt.pageSizeParam, 10)
+			var next string
+			if pt.linkedPages && number*size < len(comments) {
+				next = fmt.Sprintf("%s%s?%s=%d&page=%d", apiURL, r.Path, pt.pageSizeParam, size, number+1)
+			}
+			return http.StatusOK, pt.response(page(comments, number, size), next)
+		case r.Method == http.MethodPost && r.Path == pt.commentsPath:
+			return http.StatusCreated, nil
+		case r.Method == pt.updateMethod && strings.HasPrefix(r.Path, pt.updatePath+"/"):
+			return http.StatusOK, nil
+		}
+		return http.StatusNotFound, nil
+	}).URL
+	return apiURL
+}
+
+// newPublisher returns the publisher configured by the environment variables with the API URL and the token
+func (pt publisherTest) newPublisher(t *testing.T, apiURL, token string) (Publisher, error) {
+	t.Helper()
+	for _, name := range []string{"PENNYWISE_PULL_REQUEST", "GITHUB_TOKEN", "GITLAB_TOKEN", "BITBUCKET_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	for name, value := range pt.env(apiURL, token) {
+		t.Setenv(name, value)
+	}
+	return NewPublisher(pt.name)
+}
+
+func TestPublisherPublish(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []testComment
+		// updated is the ID of the updated comment, 0 if the comment is created
+		updated int64
+		// pages and linkedPages are the numbers of the comment pages requested by the publishers
+		// requesting pages until an empty one and by the ones following the next page URL
+		pages, linkedPages int
+	}{
+		{name: "creates the comment", comments: testComments(2), pages: 1, linkedPages: 1},
+		{
+			name:     "updates the comment with the marker",
+			comments: append(testComments(2), testComment{ID: 42, Body: CommentBody("old diff")}),
+			updated:  42, pages: 1, linkedPages: 1,
+		},
+		{
+			name:     "finds the comment on the next page",
+			comments: append(testComments(100), testComment{ID: 142, Body: CommentBody("old diff")}),
+			updated:  142, pages: 2, linkedPages: 2,
+		},
+		{name: "creates the comment after all the pages", comments: testComments(200), pages: 3, linkedPages: 2},
+	}
+	for _, pt := range publisherTests {
+		for _, tt := range tests {
+			t.Run(pt.name+"/"+tt.name, func(t *testing.T) {
+				api := &fakeAPI{}
+				p, err := pt.newPublisher(t, pt.serve(t, api, tt.comments), "token")
+				if err != nil {
+					t.Fatalf("NewPublisher() error = %v", err)
+				}
+
+				body := CommentBody("new diff")
+				if err := p.Publish(body); err != nil {
+					t.Fatalf("Publish() error = %v", err)
+				}
+				writes := api.writes()
+				if len(writes) != 1 {
+					t.Fatalf("got %d writes, want 1", len(writes))
+				}
+				method, path := http.MethodPost, pt.commentsPath
+				if tt.updated != 0 {
+					method, path = pt.updateMethod, fmt.Sprintf("%s/%d", pt.updatePath, tt.updated)
+				}
+				if writes[0].Method != method || writes[0].Path != path {
+					t.Errorf("got %s %s, want %s %s", writes[0].Method, writes[0].Path, method, path)
+				}
+				if got := pt.body(writes[0]); got != body {
+					t.Errorf("got body %v, want %q", got, body)
+				}
+
+				wantPages := tt.pages
+				if pt.linkedPages {
+					wantPages = tt.linkedPages
+				}
+				if pages := api.pages(); pages != wantPages {
+					t.Errorf("got %d comment pages requested, want %d", pages, wantPages)
+				}
+				for _, r := range api.requests {
+					if r.Method == http.MethodGet && queryInt(r.Query, pt.pageSizeParam, 0) != pt.pageSize {
+						t.Errorf("got %s=%s, want %d", pt.pageSizeParam, r.Query.Get(pt.pageSizeParam), pt.pageSize)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPublisherPublishError(t *testing.T) {
+	for _, pt := range publisherTests {
+		t.Run(pt.name, func(t *testing.T) {
+			api := &fakeAPI{}
+			p, err := pt.newPublisher(t, pt.serve(t, api, nil), "wrong")
+			if err != nil {
+				t.Fatalf("NewPublisher() error = %v", err)
+			}
+			if err := p.Publish(CommentBody("new diff")); err == nil {
+				t.Fatal("Publish() returned no error on an unauthorized request")
+			}
+			if writes := api.writes(); len(writes) != 0 {
+				t.Errorf("got %d writes after the failed comments request, want 0", len(writes))
+			}
+		})
+	}
+}
+
+func TestNewPublisherWithoutPullRequest(t *testing.T) {
+	for _, pt := range publisherTests {
+		t.Run(pt.name, func(t *testing.T) {
+			env := pt.env("https://api.example.com", "token")
+			for _, name := range pt.pullRequestEnv {
+				env[name] = ""
+			}
+			for name, value := range env {
+				t.Setenv(name, value)
+			}
+			if _, err := NewPublisher(pt.name); err == nil {
+				t.Errorf("NewPublisher(%s) returned no error without a pull request", pt.name)
+			}
+		})
+	}
+}
+
+func TestCommentBody(t *testing.T) {
+	body := CommentBody("cost diff")
+	if body != Marker+"\ncost diff" {
+		t.Errorf("CommentBody() = %q", body)
+	}
+}
+
+func TestNewPublisherUnsupported(t *testing.T) {
+	if _, err := NewPublisher("gitea"); err == nil {
+		t.Error("NewPublisher(gitea) returned no error")
+	}
+}
@@ -0,0 +1,100 @@
+package recommendation
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+)
+
+// DefaultRuleSet returns the built-in rules
+func DefaultRuleSet() RuleSet {
+	return RuleSet{Rules: Rules, ConsolidationRules: ConsolidationRules}
+}
+
+// LoadRuleSet returns the built-in rules with the rules of the file at the path or the one set by the
+// PENNYWISE_RECOMMENDATION_RULES environment variable added, a rule named as a built-in one replaces it.
+// Only the built-in rules are returned if there is no rules file.
+func LoadRuleSet(path string) (RuleSet, error) {
+	if path == "" {
+		path = os.Getenv(pkg.RecommendationRulesEnv)
+	}
+	if path == "" {
+		return DefaultRuleSet(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("error while reading recommendation rules file %s", err)
+	}
+
+	var file RuleSet
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return RuleSet{}, fmt.Errorf("unsupported file format %s for recommendation rules file", ext)
+	}
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("error while parsing recommendation rules file %s", err)
+	}
+	if err := file.validate(); err != nil {
+		return RuleSet{}, fmt.Errorf("invalid recommendation rules file %s: %w", path, err)
+	}
+	return DefaultRuleSet().merge(file), nil
+}
+
+// validate checks that every rule has a name, the resources it applies to and the values it suggests
+func (rs RuleSet) validate() error {
+	for i, r := range rs.Rules {
+		switch {
+		case r.Name == "":
+			return fmt.Errorf("rule %d has no name", i+1)
+		case len(r.ResourceTypes) == 0:
+			return fmt.Errorf("rule %s has no resource types", r.Name)
+		case r.Attribute == "":
+			return fmt.Errorf("rule %s has no attribute", r.Name)
+		case len(r.Replacements) == 0 && len(r.Prefixes) == 0:
+			return fmt.Errorf("rule %s has no replacements or prefixes", r.Name)
+		}
+	}
+	for i, r := range rs.ConsolidationRules {
+		switch {
+		case r.Name == "":
+			return fmt.Errorf("consolidation rule %d has no name", i+1)
+		case r.ResourceType == "":
+			return fmt.Errorf("consolidation rule %s has no resource type", r.Name)
+		}
+	}
+	return nil
+}
+
+// merge returns the rules with the other rules added, the rules of other replace the ones with the same name
+func (rs RuleSet) merge(other RuleSet) RuleSet {
+	var result RuleSet
+	replaced := make(map[string]bool)
+	for _, r := range other.Rules {
+		replaced[r.Name] = true
+	}
+	for _, r := range rs.Rules {
+		if !replaced[r.Name] {
+			result.Rules = append(result.Rules, r)
+		}
+	}
+	result.Rules = append(result.Rules, other.Rules...)
+
+	replaced = make(map[string]bool)
+	for _, r := range other.ConsolidationRules {
+		replaced[r.Name] = true
+	}
+	for _, r := range rs.ConsolidationRules {
+		if !replaced[r.Name] {
+			result.ConsolidationRules = append(result.ConsolidationRules, r)
+		}
+	}
+	result.ConsolidationRules = append(result.ConsolidationRules, other.ConsolidationRules...)
+	return result
+}
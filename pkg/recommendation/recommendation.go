@@ -0,0 +1,314 @@
+package recommendation
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	bold      = color.New(color.Bold)
+	underline = color.New(color.Underline)
+)
+
+// Candidate is a resource with the attribute changed to the value suggested by the rule, to be priced
+type Candidate struct {
+	Rule      Rule
+	Address   string
+	Current   string
+	Suggested string
+	// Resource is the resource with the suggested value
+	Resource schema.ResourceDef
+}
+
+// Apply returns the candidate of the resource if the rule suggests a cheaper value for its attribute
+func (r Rule) Apply(res schema.ResourceDef) (Candidate, bool) {
+	if !r.appliesTo(res.Type) {
+		return Candidate{}, false
+	}
+	value, ok := getAttribute(res.Values, strings.Split(r.Attribute, "."))
+	if !ok {
+		return Candidate{}, false
+	}
+	current, ok := value.(string)
+	if !ok {
+		return Candidate{}, false
+	}
+	suggested, ok := r.Suggest(current)
+	if !ok || suggested == current {
+		return Candidate{}, false
+	}
+	values, ok := setAttribute(res.Values, strings.Split(r.Attribute, "."), suggested).(map[string]interface{})
+	if !ok {
+		return Candidate{}, false
+	}
+	res.Values = values
+	return Candidate{Rule: r, Address: res.Address, Current: current, Suggested: suggested, Resource: res}, true
+}
+
+// Module returns the module with only the resources the rule suggests a cheaper value for, with the suggested values,
+// and their candidates
+func Module(module schema.ModuleDef, rule Rule) (schema.ModuleDef, []Candidate) {
+	var candidates []Candidate
+	result, _ := module.MapResources(rule.candidates(&candidates))
+	return result, candidates
+}
+
+// Resources returns the resources the rule suggests a cheaper value for, with the suggested values, and their candidates
+func Resources(resources []schema.ResourceDef, rule Rule) ([]schema.ResourceDef, []Candidate) {
+	var candidates []Candidate
+	result := schema.MapResources(resources, rule.candidates(&candidates))
+	return result, candidates
+}
+
+// candidates returns the function changing the resources to the values suggested by the rule,
+// their candidates are appended to the slice
+func (r Rule) candidates(candidates *[]Candidate) func(schema.ResourceDef) (schema.ResourceDef, bool) {
+	return func(res schema.ResourceDef) (schema.ResourceDef, bool) {
+		candidate, ok := r.Apply(res)
+		if !ok {
+			return res, false
+		}
+		*candidates = append(*candidates, candidate)
+		return candidate.Resource, true
+	}
+}
+
+// Recommendation is a change to a resource attribute with its estimated savings for the period
+type Recommendation struct {
+	Rule        string
+	Description string
+	Address     string
+	Attribute   string
+	Current     string
+	Suggested   string
+	Cost        decimal.Decimal
+	Savings     decimal.Decimal
+}
+
+// Report is the recommendations for the resources of a state
+type Report struct {
+	Period          cost.Period
+	Currency        string
+	Recommendations []Recommendation
+}
+
+// Recommend returns the recommendations of the candidates which are cheaper than the resources in the state,
+// priced is the state of the candidates of each rule keyed by the rule name. The resources of the consolidation rules
+// of the rule set are checked in the state. The recommendations are sorted by their savings.
+func (rs RuleSet) Recommend(state *cost.ModularState, candidates []Candidate, priced map[string]*cost.ModularState, p cost.Period) (Report, error) {
+	report := Report{Period: p}
+	resources := state.ResourcesByAddress()
+	pricedResources := make(map[string]map[string]cost.Resource)
+	for name, s := range priced {
+		if s != nil {
+			pricedResources[name] = s.ResourcesByAddress()
+		}
+	}
+
+	for _, candidate := range candidates {
+		res, ok := resources[candidate.Address]
+		if !ok {
+			continue
+		}
+		pricedRes, ok := pricedResources[candidate.Rule.Name][candidate.Address]
+		if !ok || len(pricedRes.UnpricedComponents(candidate.Address)) > 0 {
+			continue
+		}
+		current, err := res.CostForPeriod(p)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to get cost of resource %s: %w", candidate.Address, err)
+		}
+		suggested, err := pricedRes.CostForPeriod(p)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to get cost of resource %s with %s: %w", candidate.Address, candidate.Rule.Name, err)
+		}
+		if report.Currency == "" {
+			report.Currency = current.Currency
+		}
+		if !suggested.Decimal.LessThan(current.Decimal) {
+			continue
+		}
+		report.Recommendations = append(report.Recommendations, Recommendation{
+			Rule:        candidate.Rule.Name,
+			Description: candidate.Rule.Description,
+			Address:     candidate.Address,
+			Attribute:   candidate.Rule.Attribute,
+			Current:     candidate.Current,
+			Suggested:   candidate.Suggested,
+			Cost:        current.Decimal,
+			Savings:     current.Decimal.Sub(suggested.Decimal),
+		})
+	}
+
+	for _, rule := range rs.ConsolidationRules {
+		recommendations, err := rule.recommend(resources, p)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Recommendations = append(report.Recommendations, recommendations...)
+	}
+
+	sort.Slice(report.Recommendations, func(i, j int) bool {
+		ri, rj := report.Recommendations[i], report.Recommendations[j]
+		if !ri.Savings.Equal(rj.Savings) {
+			return ri.Savings.GreaterThan(rj.Savings)
+		}
+		if ri.Address != rj.Address {
+			return ri.Address < rj.Address
+		}
+		return ri.Rule < rj.Rule
+	})
+	return report, nil
+}
+
+// recommend suggests removing all but the most expensive resource of the type in each region,
+// the savings are the costs of the removed resources
+func (r ConsolidationRule) recommend(resources map[string]cost.Resource, p cost.Period) ([]Recommendation, error) {
+	regions := make(map[string][]string)
+	for address, res := range resources {
+		if res.Type == r.ResourceType {
+			regions[res.Region] = append(regions[res.Region], address)
+		}
+	}
+
+	var recommendations []Recommendation
+	for region, addresses := range regions {
+		if len(addresses) < 2 {
+			continue
+		}
+		costs := make(map[string]decimal.Decimal)
+		for _, address := range addresses {
+			c, err := resources[address].CostForPeriod(p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get cost of resource %s: %w", address, err)
+			}
+			costs[address] = c.Decimal
+		}
+		sort.Slice(addresses, func(i, j int) bool {
+			if !costs[addresses[i]].Equal(costs[addresses[j]]) {
+				return costs[addresses[i]].GreaterThan(costs[addresses[j]])
+			}
+			return addresses[i] < addresses[j]
+		})
+		kept := addresses[0]
+		if region == "" {
+			region = "unknown region"
+		}
+		for _, address := range addresses[1:] {
+			recommendations = append(recommendations, Recommendation{
+				Rule:        r.Name,
+				Description: fmt.Sprintf("%s (%d in %s)", r.Description, len(addresses), region),
+				Address:     address,
+				Attribute:   "resource",
+				Current:     "exists",
+				Suggested:   fmt.Sprintf("removed, shares %s", kept),
+				Cost:        costs[address],
+				Savings:     costs[address],
+			})
+		}
+	}
+	return recommendations, nil
+}
+
+// Savings returns the total estimated savings of the recommendations
+func (r Report) Savings() decimal.Decimal {
+	var total decimal.Decimal
+	for _, rec := range r.Recommendations {
+		total = total.Add(rec.Savings)
+	}
+	return total
+}
+
+// String returns a string to show the recommendations with the attribute to change and their savings
+func (r Report) String() string {
+	if len(r.Recommendations) == 0 {
+		return bold.Sprint("Recommendations") + "\nNo cheaper alternatives found"
+	}
+	ac := cost.MoneyFormatter(r.Currency, r.Period.Precision())
+
+	t := table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.Style().Options.SeparateColumns = false
+	t.Style().Options.SeparateRows = false
+	t.Style().Options.SeparateHeader = false
+	t.Style().Format.Header = text.FormatDefault
+	t.AppendHeader(table.Row{
+		underline.Sprint("Resource"),
+		underline.Sprint("Rule"),
+		underline.Sprint("Attribute"),
+		underline.Sprint("Change"),
+		underline.Sprint(r.Period.Title()),
+		underline.Sprint("Savings"),
+	})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 2, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 3, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 4, Align: text.AlignLeft, AlignHeader: text.AlignLeft},
+		{Number: 5, Align: text.AlignRight, AlignHeader: text.AlignRight},
+		{Number: 6, Align: text.AlignRight, AlignHeader: text.AlignRight},
+	})
+	for _, rec := range r.Recommendations {
+		t.AppendRow(table.Row{rec.Address, rec.Rule, rec.Attribute, fmt.Sprintf("%s -> %s", rec.Current, rec.Suggested),
+			ac.FormatMoney(rec.Cost), ac.FormatMoney(rec.Savings)})
+	}
+	return fmt.Sprintf("%s\n%s\n%s:    %s", bold.Sprint("Recommendations"), t.Render(),
+		bold.Sprintf("Estimated savings (%s)", strings.ToLower(r.Period.Title())), ac.FormatMoney(r.Savings()))
+}
+
+// getAttribute returns the value at the path of the attribute in the nested maps and lists
+func getAttribute(value interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil, false
+		}
+		return getAttribute(child, path[1:])
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return getAttribute(v[index], path[1:])
+	}
+	return nil, false
+}
+
+// setAttribute returns a copy of the value with the attribute at the path set, the maps and lists on the path are copied
+// so the original value isn't changed
+func setAttribute(value interface{}, path []string, attribute interface{}) interface{} {
+	if len(path) == 0 {
+		return attribute
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			result[k] = child
+		}
+		result[path[0]] = setAttribute(v[path[0]], path[1:], attribute)
+		return result
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return value
+		}
+		result := make([]interface{}, len(v))
+		copy(result, v)
+		result[index] = setAttribute(v[index], path[1:], attribute)
+		return result
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package recommendation
+
+import (
+	"strings"
+)
+
+// Rule suggests a cheaper equivalent value for an attribute of the resources of a type
+type Rule struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// ResourceTypes are the types of the resources the rule applies to
+	ResourceTypes []string `json:"resource_types" yaml:"resource_types"`
+	// Attribute is the path of the attribute, the nested blocks are separated by dots like os_disk.0.storage_account_type
+	Attribute string `json:"attribute" yaml:"attribute"`
+	// Replacements maps the current values to their cheaper equivalents
+	Replacements map[string]string `json:"replacements" yaml:"replacements"`
+	// Prefixes maps the prefixes of the current values to their cheaper equivalents, like the instance families
+	Prefixes map[string]string `json:"prefixes" yaml:"prefixes"`
+}
+
+// ConsolidationRule suggests sharing a single resource instead of having many of the same type in a region
+type ConsolidationRule struct {
+	Name         string `json:"name" yaml:"name"`
+	Description  string `json:"description" yaml:"description"`
+	ResourceType string `json:"resource_type" yaml:"resource_type"`
+}
+
+// RuleSet is the rules the resources are checked against
+type RuleSet struct {
+	Rules              []Rule              `json:"rules" yaml:"rules"`
+	ConsolidationRules []ConsolidationRule `json:"consolidation_rules" yaml:"consolidation_rules"`
+}
+
+// gravitonFamilies are the instance families with a newer Graviton equivalent
+var gravitonFamilies = map[string]string{
+	"m4.":  "m7g.",
+	"m5.":  "m7g.",
+	"m5a.": "m7g.",
+	"m6i.": "m7g.",
+	"c4.":  "c7g.",
+	"c5.":  "c7g.",
+	"c5a.": "c7g.",
+	"c6i.": "c7g.",
+	"r4.":  "r7g.",
+	"r5.":  "r7g.",
+	"r5a.": "r7g.",
+	"r6i.": "r7g.",
+	"t2.":  "t4g.",
+	"t3.":  "t4g.",
+	"t3a.": "t4g.",
+}
+
+var gp3Volumes = map[string]string{
+	"gp2": "gp3",
+}
+
+var azureStandardDisks = map[string]string{
+	"Premium_LRS": "StandardSSD_LRS",
+	"Premium_ZRS": "StandardSSD_ZRS",
+}
+
+// Rules are the built-in rules the resources are checked against
+var Rules = []Rule{
+	{
+		Name:          "aws-graviton-instance",
+		Description:   "use the newer Graviton instance generation, the workload must support arm64",
+		ResourceTypes: []string{"aws_instance"},
+		Attribute:     "instance_type",
+		Prefixes:      gravitonFamilies,
+	},
+	{
+		Name:          "aws-graviton-node-group",
+		Description:   "use the newer Graviton instance generation, the workload must support arm64",
+		ResourceTypes: []string{"aws_eks_node_group"},
+		Attribute:     "instance_types.0",
+		Prefixes:      gravitonFamilies,
+	},
+	{
+		Name:          "aws-graviton-db-instance",
+		Description:   "use the newer Graviton instance generation",
+		ResourceTypes: []string{"aws_db_instance"},
+		Attribute:     "instance_class",
+		Prefixes:      prefixed("db.", gravitonFamilies),
+	},
+	{
+		Name:          "aws-gp3-volume",
+		Description:   "use gp3 volumes, they're cheaper than gp2 with the same baseline performance",
+		ResourceTypes: []string{"aws_ebs_volume"},
+		Attribute:     "type",
+		Replacements:  gp3Volumes,
+	},
+	{
+		Name:          "aws-gp3-root-volume",
+		Description:   "use gp3 volumes, they're cheaper than gp2 with the same baseline performance",
+		ResourceTypes: []string{"aws_instance"},
+		Attribute:     "root_block_device.0.volume_type",
+		Replacements:  gp3Volumes,
+	},
+	{
+		Name:          "azure-standard-disk",
+		Description:   "use Standard SSD disks if the workload doesn't need Premium SSD performance",
+		ResourceTypes: []string{"azurerm_managed_disk"},
+		Attribute:     "storage_account_type",
+		Replacements:  azureStandardDisks,
+	},
+	{
+		Name:          "azure-standard-os-disk",
+		Description:   "use Standard SSD disks if the workload doesn't need Premium SSD performance",
+		ResourceTypes: []string{"azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine"},
+		Attribute:     "os_disk.0.storage_account_type",
+		Replacements:  azureStandardDisks,
+	},
+}
+
+// ConsolidationRules are the built-in rules the resources of the same type in a region are checked against
+var ConsolidationRules = []ConsolidationRule{
+	{
+		Name:         "aws-nat-gateway-consolidation",
+		Description:  "share a single NAT gateway in the region if high availability across zones isn't required",
+		ResourceType: "aws_nat_gateway",
+	},
+}
+
+// Suggest returns the cheaper equivalent of the value, false if there isn't any
+func (r Rule) Suggest(value string) (string, bool) {
+	if suggested, ok := r.Replacements[value]; ok {
+		return suggested, true
+	}
+	var longest string
+	for prefix := range r.Prefixes {
+		if strings.HasPrefix(value, prefix) && len(prefix) > len(longest) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return "", false
+	}
+	return r.Prefixes[longest] + strings.TrimPrefix(value, longest), true
+}
+
+// appliesTo checks if the rule applies to the resource type
+func (r Rule) appliesTo(resourceType string) bool {
+	for _, typ := range r.ResourceTypes {
+		if typ == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+func prefixed(prefix string, values map[string]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[prefix+k] = prefix + v
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package schema
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// The actions of a resource change planned by terraform
+const (
+	PlanActionCreate = "create"
+	PlanActionUpdate = "update"
+	PlanActionDelete = "delete"
+	PlanActionRead   = "read"
+	PlanActionNoOp   = "no-op"
+)
+
+// ResourceChange is the change of a resource planned by terraform, read from the resource_changes of the plan
+type ResourceChange struct {
+	// Actions are the planned actions in the order they're applied,
+	// [create, delete] is a replacement creating the new resource before destroying the old one
+	Actions []string `json:"actions"`
+	// UnknownAttributes are the paths of the attributes whose values are known only after apply
+	UnknownAttributes []string `json:"unknown_attributes,omitempty"`
+	// ReplacePaths are the paths of the attributes that force the replacement of the resource
+	ReplacePaths []string `json:"replace_paths,omitempty"`
+}
+
+// IsReplace checks if the resource is destroyed and created again
+func (c ResourceChange) IsReplace() bool {
+	return len(c.Actions) == 2 && c.hasAction(PlanActionCreate) && c.hasAction(PlanActionDelete)
+}
+
+// IsCreateBeforeDestroy checks if the resource is replaced by creating the new one before destroying the old one,
+// so both of them are billed during the replacement
+func (c ResourceChange) IsCreateBeforeDestroy() bool {
+	return c.IsReplace() && c.Actions[0] == PlanActionCreate
+}
+
+// IsNoOp checks if the resource is not changed
+func (c ResourceChange) IsNoOp() bool {
+	return len(c.Actions) == 1 && c.Actions[0] == PlanActionNoOp
+}
+
+func (c ResourceChange) hasAction(action string) bool {
+	for _, a := range c.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a short description of the change with the attributes forcing the replacement
+func (c ResourceChange) String() string {
+	var parts []string
+	switch {
+	case c.IsCreateBeforeDestroy():
+		parts = append(parts, "replaced, create before destroy")
+	case c.IsReplace():
+		parts = append(parts, "replaced, destroy before create")
+	case c.IsNoOp():
+		parts = append(parts, "no changes")
+	default:
+		parts = append(parts, strings.Join(c.Actions, ", "))
+	}
+	if len(c.ReplacePaths) > 0 {
+		parts = append(parts, fmt.Sprintf("forced by %s", strings.Join(c.ReplacePaths, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Note returns a short note about the change shown with the resource in the diffs, the replacement with the
+// attributes forcing it or, for a resource without changes, that its cost difference comes from the prices or
+// the usage. It's empty for the other changes.
+func (c ResourceChange) Note() string {
+	switch {
+	case c.IsReplace():
+		return c.String()
+	case c.IsNoOp():
+		return "no changes planned, the difference is from prices or usage"
+	}
+	return ""
+}
+
+// UnknownAttributesNote returns the note listing the attributes known only after apply, at most maxAttributes of
+// them or all of them if it's 0, empty if there are none
+func (c ResourceChange) UnknownAttributesNote(maxAttributes int) string {
+	if len(c.UnknownAttributes) == 0 {
+		return ""
+	}
+	attributes := c.UnknownAttributes
+	var more string
+	if maxAttributes > 0 && len(attributes) > maxAttributes {
+		more = fmt.Sprintf(" and %d more", len(attributes)-maxAttributes)
+		attributes = attributes[:maxAttributes]
+	}
+	return fmt.Sprintf("known after apply: %s%s", strings.Join(attributes, ", "), more)
+}
+
+// PlannedChangeNotes returns the notes about the planned change of the resource, the note of the change and the
+// attributes known only after apply listing at most maxAttributes of them, nil if the planned change isn't known
+func (r ResourceDiff) PlannedChangeNotes(maxAttributes int) []string {
+	if r.PlannedChange == nil {
+		return nil
+	}
+	var notes []string
+	if note := r.PlannedChange.Note(); note != "" {
+		notes = append(notes, note)
+	}
+	if note := r.PlannedChange.UnknownAttributesNote(maxAttributes); note != "" {
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// PlannedChanges returns the planned changes of the resources keyed by their address,
+// the resources without a planned change are left out
+func (s *Submission) PlannedChanges() map[string]ResourceChange {
+	changes := make(map[string]ResourceChange)
+	for _, res := range s.Resources {
+		if res.Change != nil {
+			changes[res.Address] = *res.Change
+		}
+	}
+	return changes
+}
+
+// SetPlannedChanges sets the planned changes of the resources from the changes map keyed by the resource address
+func (s *ModularStateDiff) SetPlannedChanges(changes map[string]ResourceChange) {
+	for name, res := range s.Resources {
+		address := res.Address
+		if address == "" {
+			address = name
+		}
+		if change, ok := changes[address]; ok {
+			change := change
+			res.PlannedChange = &change
+			s.Resources[name] = res
+		}
+	}
+	for name, child := range s.ChildModules {
+		child.SetPlannedChanges(changes)
+		s.ChildModules[name] = child
+	}
+}
+
+// CreateBeforeDestroyCost returns the monthly cost billed during the replacement of the resources replaced by creating
+// the new resource before destroying the old one, the prior and the new costs of each of them, in the module and its
+// child modules
+func (s *ModularStateDiff) CreateBeforeDestroyCost() (int, decimal.Decimal) {
+	var count int
+	var total decimal.Decimal
+	for _, res := range s.Resources {
+		if res.PlannedChange != nil && res.PlannedChange.IsCreateBeforeDestroy() {
+			count++
+			total = total.Add(res.PriorCost).Add(res.NewCost)
+		}
+	}
+	for _, child := range s.ChildModules {
+		c, t := child.CreateBeforeDestroyCost()
+		count += c
+		total = total.Add(t)
+	}
+	return count, total
+}
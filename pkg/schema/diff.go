@@ -1,8 +1,10 @@
 package schema
 
 import (
+	"fmt"
 	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/shopspring/decimal"
+	"sort"
 )
 
 type Action string
@@ -26,6 +28,9 @@ type ModularStateDiff struct {
 	PriorCost decimal.Decimal
 	NewCost   decimal.Decimal
 	Action    Action
+
+	// Currency of the costs, DefaultCurrency if it's empty
+	Currency string
 }
 
 func (s *ModularStateDiff) TotalResourcesCount() int {
@@ -55,6 +60,8 @@ type ResourceDiff struct {
 	PriorCost      decimal.Decimal
 	NewCost        decimal.Decimal
 	Action         Action
+	// PlannedChange is the change of the resource planned by terraform, nil if it's not known
+	PlannedChange *ResourceChange
 }
 
 // ComponentDiff type to show diff of a Component
@@ -67,3 +74,223 @@ type ComponentDiff struct {
 	Action   Action
 	CostDiff decimal.Decimal
 }
+
+// Costs returns the prior and the new cost of the component
+func (c ComponentDiff) Costs() (decimal.Decimal, decimal.Decimal) {
+	var prior, current decimal.Decimal
+	if c.CompareTo != nil {
+		prior = c.CompareTo.Cost().Decimal
+	}
+	if c.Current != nil {
+		current = c.Current.Cost().Decimal
+	}
+	switch c.Action {
+	case ActionCreate:
+		if c.Current == nil {
+			current = c.Component.Cost().Decimal
+		}
+	case ActionRemove:
+		if c.CompareTo == nil {
+			prior = c.Component.Cost().Decimal
+		}
+	}
+	return prior, current
+}
+
+// UpfrontCosts returns the prior and the new one-time upfront cost of the component
+func (c ComponentDiff) UpfrontCosts() (decimal.Decimal, decimal.Decimal) {
+	var prior, current decimal.Decimal
+	if c.CompareTo != nil {
+		prior = c.CompareTo.UpfrontCost().Decimal
+	}
+	if c.Current != nil {
+		current = c.Current.UpfrontCost().Decimal
+	}
+	switch c.Action {
+	case ActionCreate:
+		if c.Current == nil {
+			current = c.Component.UpfrontCost().Decimal
+		}
+	case ActionRemove:
+		if c.CompareTo == nil {
+			prior = c.Component.UpfrontCost().Decimal
+		}
+	}
+	return prior, current
+}
+
+// UpfrontCosts returns the prior and the new one-time upfront cost of the module and its child modules
+func (s *ModularStateDiff) UpfrontCosts() (decimal.Decimal, decimal.Decimal) {
+	var prior, current decimal.Decimal
+	for _, res := range s.Resources {
+		for _, diffs := range res.ComponentDiffs {
+			for _, c := range diffs {
+				p, n := c.UpfrontCosts()
+				prior = prior.Add(p)
+				current = current.Add(n)
+			}
+		}
+	}
+	for _, child := range s.ChildModules {
+		p, n := child.UpfrontCosts()
+		prior = prior.Add(p)
+		current = current.Add(n)
+	}
+	return prior, current
+}
+
+// UpfrontEstimated returns true if an upfront payment of the module or its child modules is estimated from the payment
+// option instead of being priced
+func (s *ModularStateDiff) UpfrontEstimated() bool {
+	for _, res := range s.Resources {
+		for _, diffs := range res.ComponentDiffs {
+			for _, c := range diffs {
+				for _, comp := range []*cost.Component{&c.Component, c.Current, c.CompareTo} {
+					if comp != nil && comp.UpfrontEstimated {
+						return true
+					}
+				}
+			}
+		}
+	}
+	for _, child := range s.ChildModules {
+		if child.UpfrontEstimated() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReservedUpfront sets the upfront payments of the reserved instance hours of the components by the usage of the
+// resources in the current and the compared submissions, the attributes maps are keyed by the resource address
+func (s *ModularStateDiff) SetReservedUpfront(current, compareTo map[string]cost.ResourceAttributes) {
+	for name, res := range s.Resources {
+		address := res.Address
+		if address == "" {
+			address = name
+		}
+		currentUsage, priorUsage := current[address].Usage, compareTo[address].Usage
+		for _, diffs := range res.ComponentDiffs {
+			for i, c := range diffs {
+				if c.Current != nil {
+					comp := cost.ReservedUpfront(*c.Current, currentUsage)
+					c.Current = &comp
+				}
+				if c.CompareTo != nil {
+					comp := cost.ReservedUpfront(*c.CompareTo, priorUsage)
+					c.CompareTo = &comp
+				}
+				switch c.Action {
+				case ActionCreate:
+					c.Component = cost.ReservedUpfront(c.Component, currentUsage)
+				case ActionRemove:
+					c.Component = cost.ReservedUpfront(c.Component, priorUsage)
+				}
+				diffs[i] = c
+			}
+		}
+	}
+	for name, child := range s.ChildModules {
+		child.SetReservedUpfront(current, compareTo)
+		s.ChildModules[name] = child
+	}
+}
+
+// PricingError returns the error of the component in either of the states if its price could not be found
+func (c ComponentDiff) PricingError() error {
+	for _, comp := range []*cost.Component{c.Current, &c.Component, c.CompareTo} {
+		if comp != nil && !comp.IsPriced() {
+			return comp.Error
+		}
+	}
+	return nil
+}
+
+// UnpricedComponents returns the components of the modules and resources that their price could not be found
+// in either of the states, sorted by the resource address and the component name
+func (s *ModularStateDiff) UnpricedComponents() []cost.UnpricedComponent {
+	var unpriced []cost.UnpricedComponent
+	moduleUnpricedComponents(*s, "", &unpriced)
+	sort.SliceStable(unpriced, func(i, j int) bool {
+		if unpriced[i].Address != unpriced[j].Address {
+			return unpriced[i].Address < unpriced[j].Address
+		}
+		return unpriced[i].Component.Name < unpriced[j].Component.Name
+	})
+	return unpriced
+}
+
+func moduleUnpricedComponents(s ModularStateDiff, path string, unpriced *[]cost.UnpricedComponent) {
+	for address, res := range s.Resources {
+		if path != "" {
+			address = path + "." + address
+		}
+		for label, diffs := range res.ComponentDiffs {
+			for _, c := range diffs {
+				err := c.PricingError()
+				if err == nil {
+					continue
+				}
+				component := c.Component
+				component.Error = err
+				*unpriced = append(*unpriced, cost.UnpricedComponent{Address: address, Label: label, Component: component})
+			}
+		}
+	}
+	for name, child := range s.ChildModules {
+		childPath := name
+		if path != "" {
+			childPath = path + "." + name
+		}
+		moduleUnpricedComponents(child, childPath, unpriced)
+	}
+}
+
+// ConvertCurrency converts the costs of the modules, resources and components to the currency, the components are
+// converted by cost.ExchangeRates.ConvertComponent like the ones of cost.ModularState.
+// The costs without a currency are in cost.DefaultCurrency.
+func (s *ModularStateDiff) ConvertCurrency(r *cost.ExchangeRates, to string) error {
+	rate, err := r.Rate(s.Currency, to)
+	if err != nil {
+		return err
+	}
+	s.PriorCost = s.PriorCost.Mul(rate)
+	s.NewCost = s.NewCost.Mul(rate)
+	s.Currency = to
+
+	for address, res := range s.Resources {
+		res.PriorCost = res.PriorCost.Mul(rate)
+		res.NewCost = res.NewCost.Mul(rate)
+		componentDiffs := make(map[string][]ComponentDiff, len(res.ComponentDiffs))
+		for name, diffs := range res.ComponentDiffs {
+			for _, c := range diffs {
+				c.Component, err = r.ConvertComponent(c.Component, to)
+				if err != nil {
+					return fmt.Errorf("failed to convert cost of resource %s: %w", address, err)
+				}
+				for _, comp := range []**cost.Component{&c.Current, &c.CompareTo} {
+					if *comp == nil {
+						continue
+					}
+					converted, err := r.ConvertComponent(**comp, to)
+					if err != nil {
+						return fmt.Errorf("failed to convert cost of resource %s: %w", address, err)
+					}
+					*comp = &converted
+				}
+				c.CostDiff = c.CostDiff.Mul(rate)
+				componentDiffs[name] = append(componentDiffs[name], c)
+			}
+		}
+		res.ComponentDiffs = componentDiffs
+		s.Resources[address] = res
+	}
+	for name, child := range s.ChildModules {
+		err = child.ConvertCurrency(r, to)
+		if err != nil {
+			return err
+		}
+		s.ChildModules[name] = child
+	}
+	return nil
+}
@@ -6,3 +6,31 @@ type ModuleDef struct {
 	ChildModules []ModuleDef   `json:"child_modules"`
 	Resources    []ResourceDef `json:"resources"`
 }
+
+// MapResources returns the module with only the resources kept by fn, changed by it, and the number of those
+// resources. The child modules without any kept resources are left out.
+func (m ModuleDef) MapResources(fn func(ResourceDef) (ResourceDef, bool)) (ModuleDef, int) {
+	result := ModuleDef{Address: m.Address}
+	result.Resources = MapResources(m.Resources, fn)
+	count := len(result.Resources)
+	for _, child := range m.ChildModules {
+		childModule, childCount := child.MapResources(fn)
+		if childCount == 0 {
+			continue
+		}
+		result.ChildModules = append(result.ChildModules, childModule)
+		count += childCount
+	}
+	return result, count
+}
+
+// MapResources returns the resources kept by fn, changed by it
+func MapResources(resources []ResourceDef, fn func(ResourceDef) (ResourceDef, bool)) []ResourceDef {
+	var result []ResourceDef
+	for _, res := range resources {
+		if mapped, ok := fn(res); ok {
+			result = append(result, mapped)
+		}
+	}
+	return result
+}
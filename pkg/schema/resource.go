@@ -1,5 +1,11 @@
 package schema
 
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/usage"
+)
+
 // ResourceDef is a single resource definition.
 type ResourceDef struct {
 	Address      string                 `json:"address"`
@@ -8,4 +14,30 @@ type ResourceDef struct {
 	RegionCode   string                 `json:"region_code"`
 	ProviderName ProviderName           `json:"provider_name"`
 	Values       map[string]interface{} `json:"values"`
+	// Change is the change of the resource planned by terraform, nil if it's not read from a plan.
+	// It's only used locally to show the diffs and isn't sent with the submission.
+	Change *ResourceChange `json:"-"`
+}
+
+// Attributes returns the region, the tags and the usage keys of the resource.
+// The tags include the default tags of the provider from tags_all when it's known.
+func (r ResourceDef) Attributes() cost.ResourceAttributes {
+	tags := make(map[string]string)
+	for _, key := range []string{"tags_all", "tags"} {
+		values, ok := r.Values[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k, v := range values {
+			if v == nil {
+				continue
+			}
+			tags[k] = fmt.Sprint(v)
+		}
+	}
+	return cost.ResourceAttributes{
+		Region: r.RegionCode,
+		Tags:   tags,
+		Usage:  usage.Values(r.Type, r.Values),
+	}
 }
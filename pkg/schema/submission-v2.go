@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/sony/sonyflake"
 	"io/ioutil"
 	"os"
@@ -28,6 +29,15 @@ func (s *SubmissionV2) GetResources() []ResourceDef {
 	return resources
 }
 
+// ResourceAttributes returns the region and tags of the resources keyed by their address
+func (s *SubmissionV2) ResourceAttributes() map[string]cost.ResourceAttributes {
+	attributes := make(map[string]cost.ResourceAttributes)
+	for _, res := range s.GetResources() {
+		attributes[res.Address] = res.Attributes()
+	}
+	return attributes
+}
+
 func getModuleResources(module ModuleDef) []ResourceDef {
 	var resources []ResourceDef
 	resources = append(resources, module.Resources...)
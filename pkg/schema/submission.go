@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/cost"
 	"github.com/sony/sonyflake"
 	"io/ioutil"
 	"os"
@@ -44,6 +45,15 @@ func CreateSubmission(resources []ResourceDef) (*Submission, error) {
 	}, nil
 }
 
+// ResourceAttributes returns the region and tags of the resources keyed by their address
+func (s *Submission) ResourceAttributes() map[string]cost.ResourceAttributes {
+	attributes := make(map[string]cost.ResourceAttributes)
+	for _, res := range s.Resources {
+		attributes[res.Address] = res.Attributes()
+	}
+	return attributes
+}
+
 // StoreAsFile stores the submission as a file in .pennywise/submissions directory
 func (s *Submission) StoreAsFile() error {
 	jsonData, err := json.MarshalIndent(*s, "", "  ")
@@ -0,0 +1,8 @@
+package schema
+
+// Workspace is a kaytu workspace, submissions and pricing settings are scoped to a workspace
+type Workspace struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,461 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultChunkSize is the default maximum number of resources sent in a single pricing request
+	DefaultChunkSize = 500
+	// DefaultConcurrency is the default number of pricing requests sent at the same time
+	DefaultConcurrency = 4
+)
+
+// ChunkOptions defines how a submission is split and priced
+type ChunkOptions struct {
+	// ChunkSize is the maximum number of resources in each chunk
+	ChunkSize int
+	// Concurrency is the maximum number of chunks priced at the same time
+	Concurrency int
+	// Progress is called each time a chunk is priced, it can be nil
+	Progress func(done, total int)
+}
+
+// GetStateCostV2Chunked splits the submission by its modules into chunks with at most opts.ChunkSize resources,
+// prices the chunks concurrently and merges the results into a single cost.ModularState.
+func GetStateCostV2Chunked(client ServerClient, req schema.SubmissionV2, opts ChunkOptions) (*cost.ModularState, error) {
+	opts = opts.withDefaults()
+
+	chunks := SplitSubmissionV2(req, opts.ChunkSize)
+	if len(chunks) == 1 {
+		return client.GetStateCostV2(chunks[0])
+	}
+
+	states := make([]*cost.ModularState, len(chunks))
+	err := priceChunks(len(chunks), opts, func(i int) (err error) {
+		states[i], err = client.GetStateCostV2(chunks[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var state cost.ModularState
+	for _, chunkState := range states {
+		mergeModularState(&state, *chunkState)
+	}
+	return &state, nil
+}
+
+// GetStateCostChunked splits the resources of the submission into chunks with at most opts.ChunkSize resources,
+// prices the chunks concurrently and merges the results into a single cost.State.
+func GetStateCostChunked(client ServerClient, req schema.Submission, opts ChunkOptions) (*cost.State, error) {
+	opts = opts.withDefaults()
+
+	chunks := SplitSubmission(req, opts.ChunkSize)
+	if len(chunks) == 1 {
+		return client.GetStateCost(chunks[0])
+	}
+
+	states := make([]*cost.State, len(chunks))
+	err := priceChunks(len(chunks), opts, func(i int) (err error) {
+		states[i], err = client.GetStateCost(chunks[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state := cost.State{Resources: make(map[string]cost.Resource)}
+	for _, chunkState := range states {
+		for address, res := range chunkState.Resources {
+			state.Resources[address] = res
+		}
+	}
+	return &state, nil
+}
+
+// GetSubmissionsDiffChunked splits the resources of both submissions into chunks with at most opts.ChunkSize addresses,
+// the resources with the same address are compared in the same chunk. The chunks are diffed concurrently and the results
+// are merged into a single schema.StateDiff.
+func GetSubmissionsDiffChunked(client ServerClient, req schema.SubmissionsDiff, opts ChunkOptions) (*schema.StateDiff, error) {
+	opts = opts.withDefaults()
+
+	chunks := SplitSubmissionsDiff(req, opts.ChunkSize)
+	if len(chunks) == 1 {
+		return client.GetSubmissionsDiff(chunks[0])
+	}
+
+	diffs := make([]*schema.StateDiff, len(chunks))
+	err := priceChunks(len(chunks), opts, func(i int) (err error) {
+		diffs[i], err = client.GetSubmissionsDiff(chunks[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diff := schema.StateDiff{Resources: make(map[string]schema.ResourceDiff)}
+	for _, chunkDiff := range diffs {
+		for address, res := range chunkDiff.Resources {
+			diff.Resources[address] = res
+		}
+		diff.PriorCost = diff.PriorCost.Add(chunkDiff.PriorCost)
+		diff.NewCost = diff.NewCost.Add(chunkDiff.NewCost)
+	}
+	return &diff, nil
+}
+
+// GetSubmissionsDiffV2Chunked splits the resources of both submissions into chunks with at most opts.ChunkSize addresses
+// keeping their module paths, the resources with the same address are compared in the same chunk. The chunks are diffed
+// concurrently and the results are merged into a single schema.ModularStateDiff.
+func GetSubmissionsDiffV2Chunked(client ServerClient, req schema.SubmissionsDiffV2, opts ChunkOptions) (*schema.ModularStateDiff, error) {
+	opts = opts.withDefaults()
+
+	chunks := SplitSubmissionsDiffV2(req, opts.ChunkSize)
+	if len(chunks) == 1 {
+		return client.GetSubmissionsDiffV2(chunks[0])
+	}
+
+	diffs := make([]*schema.ModularStateDiff, len(chunks))
+	err := priceChunks(len(chunks), opts, func(i int) (err error) {
+		diffs[i], err = client.GetSubmissionsDiffV2(chunks[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var diff schema.ModularStateDiff
+	for _, chunkDiff := range diffs {
+		mergeModularStateDiff(&diff, *chunkDiff)
+	}
+	return &diff, nil
+}
+
+// withDefaults returns the options with the default chunk size and concurrency if they're not set
+func (opts ChunkOptions) withDefaults() ChunkOptions {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	return opts
+}
+
+// priceChunks calls price for each of the chunks with at most opts.Concurrency calls at the same time,
+// price stores the result of the chunk at its index. The first error is returned after all the chunks are done.
+func priceChunks(total int, opts ChunkOptions, price func(i int) error) error {
+	type chunkResult struct {
+		index int
+		err   error
+	}
+	jobs := make(chan int)
+	results := make(chan chunkResult)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency && w < total; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results <- chunkResult{index: i, err: price(i)}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := 0; i < total; i++ {
+			jobs <- i
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var firstErr error
+	var done int
+	for res := range results {
+		done++
+		if opts.Progress != nil {
+			opts.Progress(done, total)
+		}
+		if res.err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to get cost of chunk %d: %w", res.index, res.err)
+		}
+	}
+	return firstErr
+}
+
+// SplitSubmission splits the submission into submissions with at most chunkSize resources
+func SplitSubmission(sub schema.Submission, chunkSize int) []schema.Submission {
+	var chunks []schema.Submission
+	for start := 0; start < len(sub.Resources) || start == 0; start += chunkSize {
+		end := start + chunkSize
+		if end > len(sub.Resources) {
+			end = len(sub.Resources)
+		}
+		chunks = append(chunks, schema.Submission{
+			ID:        sub.ID,
+			CreatedAt: sub.CreatedAt,
+			ProjectId: sub.ProjectId,
+			Resources: sub.Resources[start:end],
+		})
+	}
+	return chunks
+}
+
+// SplitSubmissionsDiff splits the submissions into pairs of submissions with at most chunkSize resource addresses,
+// a resource is in the same pair as the resource with its address in the other submission
+func SplitSubmissionsDiff(req schema.SubmissionsDiff, chunkSize int) []schema.SubmissionsDiff {
+	addresses := make(map[string]bool)
+	for _, res := range req.Current.Resources {
+		addresses[res.Address] = true
+	}
+	for _, res := range req.CompareTo.Resources {
+		addresses[res.Address] = true
+	}
+
+	var chunks []schema.SubmissionsDiff
+	for _, chunk := range splitKeys(addresses, chunkSize) {
+		chunks = append(chunks, schema.SubmissionsDiff{
+			Current:   filterSubmission(req.Current, chunk),
+			CompareTo: filterSubmission(req.CompareTo, chunk),
+		})
+	}
+	return chunks
+}
+
+// SplitSubmissionsDiffV2 splits the submissions into pairs of submissions with at most chunkSize resources addresses,
+// a resource is in the same pair as the resource with its address in the same module of the other submission.
+// Every chunk keeps the module paths to its resources.
+func SplitSubmissionsDiffV2(req schema.SubmissionsDiffV2, chunkSize int) []schema.SubmissionsDiffV2 {
+	addresses := make(map[string]bool)
+	moduleResourceKeys(req.Current.RootModule, addresses)
+	moduleResourceKeys(req.CompareTo.RootModule, addresses)
+
+	var chunks []schema.SubmissionsDiffV2
+	for _, chunk := range splitKeys(addresses, chunkSize) {
+		current, compareTo := req.Current, req.CompareTo
+		current.RootModule, _ = filterModule(req.Current.RootModule, chunk)
+		compareTo.RootModule, _ = filterModule(req.CompareTo.RootModule, chunk)
+		chunks = append(chunks, schema.SubmissionsDiffV2{
+			Current:   current,
+			CompareTo: compareTo,
+		})
+	}
+	return chunks
+}
+
+// splitKeys returns the sorted keys split into sets of at most chunkSize keys, a single empty set if there are no keys
+func splitKeys(keys map[string]bool, chunkSize int) []map[string]bool {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	chunks := []map[string]bool{{}}
+	for _, key := range sorted {
+		if len(chunks[len(chunks)-1]) == chunkSize {
+			chunks = append(chunks, map[string]bool{})
+		}
+		chunks[len(chunks)-1][key] = true
+	}
+	return chunks
+}
+
+// filterSubmission returns the submission with only the resources with the addresses
+func filterSubmission(sub schema.Submission, addresses map[string]bool) schema.Submission {
+	filtered := sub
+	filtered.Resources = nil
+	for _, res := range sub.Resources {
+		if addresses[res.Address] {
+			filtered.Resources = append(filtered.Resources, res)
+		}
+	}
+	return filtered
+}
+
+// moduleResourceKeys adds the keys of the resources of the module and its child modules,
+// a key is the address of the module and the resource
+func moduleResourceKeys(module schema.ModuleDef, keys map[string]bool) {
+	for _, res := range module.Resources {
+		keys[moduleResourceKey(module, res)] = true
+	}
+	for _, child := range module.ChildModules {
+		moduleResourceKeys(child, keys)
+	}
+}
+
+func moduleResourceKey(module schema.ModuleDef, res schema.ResourceDef) string {
+	return module.Address + " " + res.Address
+}
+
+// filterModule returns the module with only the resources with the keys, the child modules without any of them
+// are removed. It returns false if the module has none of the resources.
+func filterModule(module schema.ModuleDef, keys map[string]bool) (schema.ModuleDef, bool) {
+	filtered := schema.ModuleDef{Address: module.Address}
+	for _, res := range module.Resources {
+		if keys[moduleResourceKey(module, res)] {
+			filtered.Resources = append(filtered.Resources, res)
+		}
+	}
+	for _, child := range module.ChildModules {
+		if filteredChild, ok := filterModule(child, keys); ok {
+			filtered.ChildModules = append(filtered.ChildModules, filteredChild)
+		}
+	}
+	return filtered, len(filtered.Resources) > 0 || len(filtered.ChildModules) > 0
+}
+
+// SplitSubmissionV2 splits the submission into submissions with at most chunkSize resources.
+// Modules are kept whole when possible, bigger modules are split by their child modules and
+// their own resources. Every chunk keeps the module path to its resources.
+func SplitSubmissionV2(sub schema.SubmissionV2, chunkSize int) []schema.SubmissionV2 {
+	var chunks []schema.SubmissionV2
+	for _, module := range splitModule(sub.RootModule, chunkSize) {
+		chunks = append(chunks, schema.SubmissionV2{
+			ID:         sub.ID,
+			Version:    sub.Version,
+			CreatedAt:  sub.CreatedAt,
+			ProjectId:  sub.ProjectId,
+			RootModule: module,
+		})
+	}
+	return chunks
+}
+
+// splitModule returns pieces of the module containing at most chunkSize resources,
+// all the pieces have the same address as the module
+func splitModule(module schema.ModuleDef, chunkSize int) []schema.ModuleDef {
+	if moduleResourcesCount(module) <= chunkSize {
+		return []schema.ModuleDef{module}
+	}
+
+	var pieces []schema.ModuleDef
+	for start := 0; start < len(module.Resources); start += chunkSize {
+		end := start + chunkSize
+		if end > len(module.Resources) {
+			end = len(module.Resources)
+		}
+		pieces = append(pieces, schema.ModuleDef{
+			Address:   module.Address,
+			Resources: module.Resources[start:end],
+		})
+	}
+	for _, child := range module.ChildModules {
+		for _, childPiece := range splitModule(child, chunkSize) {
+			pieces = append(pieces, schema.ModuleDef{
+				Address:      module.Address,
+				ChildModules: []schema.ModuleDef{childPiece},
+			})
+		}
+	}
+
+	// pack the pieces into as few chunks as possible
+	var chunks []schema.ModuleDef
+	var chunksCount []int
+	for _, piece := range pieces {
+		count := moduleResourcesCount(piece)
+		packed := false
+		for i := range chunks {
+			if chunksCount[i]+count <= chunkSize {
+				chunks[i] = mergeModuleDef(chunks[i], piece)
+				chunksCount[i] += count
+				packed = true
+				break
+			}
+		}
+		if !packed {
+			chunks = append(chunks, piece)
+			chunksCount = append(chunksCount, count)
+		}
+	}
+	return chunks
+}
+
+// mergeModuleDef merges two pieces of the same module, child modules with the same address are merged together
+func mergeModuleDef(a, b schema.ModuleDef) schema.ModuleDef {
+	merged := schema.ModuleDef{
+		Address: a.Address,
+	}
+	merged.Resources = append(merged.Resources, a.Resources...)
+	merged.Resources = append(merged.Resources, b.Resources...)
+	merged.ChildModules = append(merged.ChildModules, a.ChildModules...)
+	for _, child := range b.ChildModules {
+		found := false
+		for i, existing := range merged.ChildModules {
+			if existing.Address == child.Address {
+				merged.ChildModules[i] = mergeModuleDef(existing, child)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.ChildModules = append(merged.ChildModules, child)
+		}
+	}
+	return merged
+}
+
+func moduleResourcesCount(module schema.ModuleDef) int {
+	count := len(module.Resources)
+	for _, child := range module.ChildModules {
+		count += moduleResourcesCount(child)
+	}
+	return count
+}
+
+// mergeModularState merges the resources and child modules of src into dst
+func mergeModularState(dst *cost.ModularState, src cost.ModularState) {
+	if len(src.Resources) > 0 && dst.Resources == nil {
+		dst.Resources = make(map[string]cost.Resource)
+	}
+	for name, res := range src.Resources {
+		dst.Resources[name] = res
+	}
+	if len(src.ChildModules) > 0 && dst.ChildModules == nil {
+		dst.ChildModules = make(map[string]cost.ModularState)
+	}
+	for name, child := range src.ChildModules {
+		existing := dst.ChildModules[name]
+		mergeModularState(&existing, child)
+		dst.ChildModules[name] = existing
+	}
+}
+
+// mergeModularStateDiff merges the resources and child modules of src into dst and adds up their costs,
+// a module diffed as created in one chunk and as removed or modified in another is modified
+func mergeModularStateDiff(dst *schema.ModularStateDiff, src schema.ModularStateDiff) {
+	if len(src.Resources) > 0 && dst.Resources == nil {
+		dst.Resources = make(map[string]schema.ResourceDiff)
+	}
+	for name, res := range src.Resources {
+		dst.Resources[name] = res
+	}
+	if len(src.ChildModules) > 0 && dst.ChildModules == nil {
+		dst.ChildModules = make(map[string]schema.ModularStateDiff)
+	}
+	for name, child := range src.ChildModules {
+		existing := dst.ChildModules[name]
+		mergeModularStateDiff(&existing, child)
+		dst.ChildModules[name] = existing
+	}
+	dst.PriorCost = dst.PriorCost.Add(src.PriorCost)
+	dst.NewCost = dst.NewCost.Add(src.NewCost)
+	if dst.Action == "" {
+		dst.Action = src.Action
+	} else if src.Action != "" && src.Action != dst.Action {
+		dst.Action = schema.ActionModify
+	}
+	if dst.Currency == "" {
+		dst.Currency = src.Currency
+	}
+}
@@ -0,0 +1,516 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/kaytu-io/pennywise/pkg/cost"
+	"github.com/kaytu-io/pennywise/pkg/schema"
+	"github.com/shopspring/decimal"
+)
+
+// fakeClient prices the resources by their "cost" value and records the number of resources of each request
+type fakeClient struct {
+	ServerClient
+
+	mu            sync.Mutex
+	requestCounts []int
+}
+
+func (c *fakeClient) record(count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestCounts = append(c.requestCounts, count)
+}
+
+func resourceCost(res schema.ResourceDef) decimal.Decimal {
+	return decimal.NewFromFloat(res.Values["cost"].(float64))
+}
+
+func pricedResource(res schema.ResourceDef) cost.Resource {
+	return cost.Resource{Components: map[string][]cost.Component{"compute": {{
+		Name:            "compute",
+		MonthlyQuantity: resourceCost(res),
+		Rate:            cost.Cost{Decimal: decimal.NewFromInt(1), Currency: "USD"},
+	}}}}
+}
+
+func (c *fakeClient) GetStateCost(req schema.Submission) (*cost.State, error) {
+	c.record(len(req.Resources))
+	state := cost.State{Resources: make(map[string]cost.Resource)}
+	for _, res := range req.Resources {
+		state.Resources[res.Address] = pricedResource(res)
+	}
+	return &state, nil
+}
+
+func (c *fakeClient) GetStateCostV2(req schema.SubmissionV2) (*cost.ModularState, error) {
+	c.record(moduleResourcesCount(req.RootModule))
+	state := priceModule(req.RootModule)
+	return &state, nil
+}
+
+func priceModule(module schema.ModuleDef) cost.ModularState {
+	state := cost.ModularState{Resources: make(map[string]cost.Resource), ChildModules: make(map[string]cost.ModularState)}
+	for _, res := range module.Resources {
+		state.Resources[res.Address] = pricedResource(res)
+	}
+	for _, child := range module.ChildModules {
+		state.ChildModules[child.Address] = priceModule(child)
+	}
+	return state
+}
+
+func (c *fakeClient) GetSubmissionsDiff(req schema.SubmissionsDiff) (*schema.StateDiff, error) {
+	c.record(len(req.Current.Resources) + len(req.CompareTo.Resources))
+	diff := schema.StateDiff{Resources: make(map[string]schema.ResourceDiff)}
+	resources := diffResources(req.Current.Resources, req.CompareTo.Resources)
+	for _, res := range resources {
+		diff.Resources[res.Address] = res
+		diff.PriorCost = diff.PriorCost.Add(res.PriorCost)
+		diff.NewCost = diff.NewCost.Add(res.NewCost)
+	}
+	return &diff, nil
+}
+
+func (c *fakeClient) GetSubmissionsDiffV2(req schema.SubmissionsDiffV2) (*schema.ModularStateDiff, error) {
+	c.record(moduleResourcesCount(req.Current.RootModule) + moduleResourcesCount(req.CompareTo.RootModule))
+	diff := diffModules(&req.Current.RootModule, &req.CompareTo.RootModule)
+	return &diff, nil
+}
+
+// diffResources diffs the resources by their addresses, a resource is created if it's only in current,
+// removed if it's only in compareTo and modified otherwise
+func diffResources(current, compareTo []schema.ResourceDef) []schema.ResourceDiff {
+	diffs := make(map[string]schema.ResourceDiff)
+	for _, res := range compareTo {
+		diffs[res.Address] = schema.ResourceDiff{Address: res.Address, PriorCost: resourceCost(res), Action: schema.ActionRemove}
+	}
+	for _, res := range current {
+		diff, ok := diffs[res.Address]
+		diff.Address = res.Address
+		diff.NewCost = resourceCost(res)
+		diff.Action = schema.ActionCreate
+		if ok {
+			diff.Action = schema.ActionModify
+		}
+		diffs[res.Address] = diff
+	}
+	var resources []schema.ResourceDiff
+	for _, diff := range diffs {
+		resources = append(resources, diff)
+	}
+	return resources
+}
+
+// diffModules diffs the modules like the pricing server, a module is created if it's only in current,
+// removed if it's only in compareTo and modified otherwise
+func diffModules(current, compareTo *schema.ModuleDef) schema.ModularStateDiff {
+	diff := schema.ModularStateDiff{Currency: "USD", Action: schema.ActionModify}
+	var currentResources, compareToResources []schema.ResourceDef
+	currentChildren := make(map[string]*schema.ModuleDef)
+	compareToChildren := make(map[string]*schema.ModuleDef)
+	switch {
+	case compareTo == nil:
+		diff.Action = schema.ActionCreate
+	case current == nil:
+		diff.Action = schema.ActionRemove
+	}
+	if current != nil {
+		currentResources = current.Resources
+		for i := range current.ChildModules {
+			currentChildren[current.ChildModules[i].Address] = &current.ChildModules[i]
+		}
+	}
+	if compareTo != nil {
+		compareToResources = compareTo.Resources
+		for i := range compareTo.ChildModules {
+			compareToChildren[compareTo.ChildModules[i].Address] = &compareTo.ChildModules[i]
+		}
+	}
+
+	diff.Resources = make(map[string]schema.ResourceDiff)
+	for _, res := range diffResources(currentResources, compareToResources) {
+		diff.Resources[res.Address] = res
+		diff.PriorCost = diff.PriorCost.Add(res.PriorCost)
+		diff.NewCost = diff.NewCost.Add(res.NewCost)
+	}
+	diff.ChildModules = make(map[string]schema.ModularStateDiff)
+	childAddresses := make(map[string]bool)
+	for address := range currentChildren {
+		childAddresses[address] = true
+	}
+	for address := range compareToChildren {
+		childAddresses[address] = true
+	}
+	for address := range childAddresses {
+		child := diffModules(currentChildren[address], compareToChildren[address])
+		diff.ChildModules[address] = child
+		diff.PriorCost = diff.PriorCost.Add(child.PriorCost)
+		diff.NewCost = diff.NewCost.Add(child.NewCost)
+	}
+	return diff
+}
+
+// testResources returns count resources of the type with the costs 1, 2, ... count
+func testResources(resourceType string, count int) []schema.ResourceDef {
+	var resources []schema.ResourceDef
+	for i := 1; i <= count; i++ {
+		resources = append(resources, schema.ResourceDef{
+			Address: fmt.Sprintf("%s.r%d", resourceType, i),
+			Type:    resourceType,
+			Values:  map[string]interface{}{"cost": float64(i)},
+		})
+	}
+	return resources
+}
+
+// testModule returns a root module with 3 resources, a module.a child with 4 resources and a module.a.module.b
+// grandchild with 5 resources, and a module.c child with 2 resources
+func testModule() schema.ModuleDef {
+	return schema.ModuleDef{
+		Resources: testResources("aws_instance", 3),
+		ChildModules: []schema.ModuleDef{
+			{
+				Address:   "module.a",
+				Resources: testResources("aws_ebs_volume", 4),
+				ChildModules: []schema.ModuleDef{
+					{Address: "module.a.module.b", Resources: testResources("aws_s3_bucket", 5)},
+				},
+			},
+			{Address: "module.c", Resources: testResources("aws_lb", 2)},
+		},
+	}
+}
+
+// modulePaths returns the resources of the module and its child modules by their addresses prefixed with the
+// addresses of the modules they're nested in
+func modulePaths(module schema.ModuleDef, prefix string, paths map[string]int) {
+	prefix += module.Address + "/"
+	for _, res := range module.Resources {
+		paths[prefix+res.Address]++
+	}
+	for _, child := range module.ChildModules {
+		modulePaths(child, prefix, paths)
+	}
+}
+
+func TestSplitSubmission(t *testing.T) {
+	for _, count := range []int{0, 1, 5, 10} {
+		for _, chunkSize := range []int{1, 3, 10, 20} {
+			t.Run(fmt.Sprintf("%d resources in chunks of %d", count, chunkSize), func(t *testing.T) {
+				sub := schema.Submission{ID: "sub", ProjectId: "project", Resources: testResources("aws_instance", count)}
+				chunks := SplitSubmission(sub, chunkSize)
+
+				wantChunks := (count + chunkSize - 1) / chunkSize
+				if wantChunks == 0 {
+					wantChunks = 1
+				}
+				if len(chunks) != wantChunks {
+					t.Errorf("got %d chunks, want %d", len(chunks), wantChunks)
+				}
+				var resources []schema.ResourceDef
+				for _, chunk := range chunks {
+					if len(chunk.Resources) > chunkSize {
+						t.Errorf("chunk has %d resources, want at most %d", len(chunk.Resources), chunkSize)
+					}
+					if chunk.ID != sub.ID || chunk.ProjectId != sub.ProjectId {
+						t.Errorf("chunk ID = %q and ProjectId = %q, want the ones of the submission", chunk.ID, chunk.ProjectId)
+					}
+					resources = append(resources, chunk.Resources...)
+				}
+				if !reflect.DeepEqual(resources, sub.Resources) {
+					t.Errorf("resources of the chunks = %v, want %v", resources, sub.Resources)
+				}
+			})
+		}
+	}
+}
+
+func TestSplitSubmissionV2(t *testing.T) {
+	sub := schema.SubmissionV2{ID: "sub", Version: "2", RootModule: testModule()}
+	want := make(map[string]int)
+	modulePaths(sub.RootModule, "", want)
+
+	for _, chunkSize := range []int{1, 2, 4, 5, 7, 14, 100} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			chunks := SplitSubmissionV2(sub, chunkSize)
+
+			got := make(map[string]int)
+			for _, chunk := range chunks {
+				if count := moduleResourcesCount(chunk.RootModule); count > chunkSize || count == 0 {
+					t.Errorf("chunk has %d resources, want 1 to %d", count, chunkSize)
+				}
+				if chunk.ID != sub.ID || chunk.Version != sub.Version {
+					t.Errorf("chunk ID = %q and Version = %q, want the ones of the submission", chunk.ID, chunk.Version)
+				}
+				modulePaths(chunk.RootModule, "", got)
+			}
+			// every resource is in exactly one chunk and in the same modules
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("resources of the chunks = %v, want %v", got, want)
+			}
+			if chunkSize >= 14 && len(chunks) != 1 {
+				t.Errorf("got %d chunks, want the whole submission in one", len(chunks))
+			}
+		})
+	}
+}
+
+func TestSplitSubmissionsDiffV2(t *testing.T) {
+	current, compareTo := testModule(), testModule()
+	compareTo.ChildModules[0].ChildModules[0].Resources = testResources("aws_s3_bucket", 2)
+	req := schema.SubmissionsDiffV2{
+		Current:   schema.SubmissionV2{RootModule: current},
+		CompareTo: schema.SubmissionV2{RootModule: compareTo},
+	}
+	wantCurrent, wantCompareTo := make(map[string]int), make(map[string]int)
+	modulePaths(current, "", wantCurrent)
+	modulePaths(compareTo, "", wantCompareTo)
+
+	for _, chunkSize := range []int{1, 3, 14, 100} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			gotCurrent, gotCompareTo := make(map[string]int), make(map[string]int)
+			for _, chunk := range SplitSubmissionsDiffV2(req, chunkSize) {
+				chunkCurrent, chunkCompareTo := make(map[string]int), make(map[string]int)
+				modulePaths(chunk.Current.RootModule, "", chunkCurrent)
+				modulePaths(chunk.CompareTo.RootModule, "", chunkCompareTo)
+				addresses := make(map[string]bool)
+				for path := range chunkCurrent {
+					addresses[path] = true
+					gotCurrent[path]++
+				}
+				for path := range chunkCompareTo {
+					addresses[path] = true
+					gotCompareTo[path]++
+				}
+				if len(addresses) > chunkSize {
+					t.Errorf("chunk has %d addresses, want at most %d", len(addresses), chunkSize)
+				}
+			}
+			// a resource in both submissions is in the same chunk, so each of them is in exactly one
+			if !reflect.DeepEqual(gotCurrent, wantCurrent) {
+				t.Errorf("current resources of the chunks = %v, want %v", gotCurrent, wantCurrent)
+			}
+			if !reflect.DeepEqual(gotCompareTo, wantCompareTo) {
+				t.Errorf("compared resources of the chunks = %v, want %v", gotCompareTo, wantCompareTo)
+			}
+		})
+	}
+}
+
+// stateCosts returns the costs of the resources of the state by their addresses prefixed with their module path
+func stateCosts(state cost.ModularState, prefix string, costs map[string]string) {
+	for address, res := range state.Resources {
+		c, err := res.Cost()
+		if err != nil {
+			panic(err)
+		}
+		costs[prefix+address] = c.Decimal.String()
+	}
+	for address, child := range state.ChildModules {
+		stateCosts(child, prefix+address+"/", costs)
+	}
+}
+
+func TestGetStateCostV2Chunked(t *testing.T) {
+	sub := schema.SubmissionV2{RootModule: testModule()}
+	unchunked, err := GetStateCostV2Chunked(&fakeClient{}, sub, ChunkOptions{ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]string)
+	stateCosts(*unchunked, "", want)
+
+	for _, chunkSize := range []int{1, 2, 3, 5, 7} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			client := &fakeClient{}
+			var progress []int
+			var mu sync.Mutex
+			state, err := GetStateCostV2Chunked(client, sub, ChunkOptions{ChunkSize: chunkSize, Concurrency: 2, Progress: func(done, total int) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress = append(progress, done)
+			}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string)
+			stateCosts(*state, "", got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("merged costs = %v, want the unchunked %v", got, want)
+			}
+			for _, count := range client.requestCounts {
+				if count > chunkSize {
+					t.Errorf("request has %d resources, want at most %d", count, chunkSize)
+				}
+			}
+			if len(progress) != len(client.requestCounts) || progress[len(progress)-1] != len(client.requestCounts) {
+				t.Errorf("progress = %v, want one call for each of the %d chunks", progress, len(client.requestCounts))
+			}
+		})
+	}
+}
+
+func TestGetStateCostChunked(t *testing.T) {
+	sub := schema.Submission{Resources: testResources("aws_instance", 10)}
+	for _, chunkSize := range []int{1, 3, 10} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			state, err := GetStateCostChunked(&fakeClient{}, sub, ChunkOptions{ChunkSize: chunkSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(state.Resources) != 10 {
+				t.Errorf("got %d resources, want 10", len(state.Resources))
+			}
+			total, err := state.Cost()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := decimal.NewFromInt(55); !total.Decimal.Equal(want) {
+				t.Errorf("Cost() = %s, want %s", total.Decimal, want)
+			}
+		})
+	}
+}
+
+func TestGetSubmissionsDiffChunked(t *testing.T) {
+	req := schema.SubmissionsDiff{
+		Current:   schema.Submission{Resources: testResources("aws_instance", 6)},
+		CompareTo: schema.Submission{Resources: testResources("aws_instance", 4)},
+	}
+	for _, chunkSize := range []int{1, 4, 6} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			diff, err := GetSubmissionsDiffChunked(&fakeClient{}, req, ChunkOptions{ChunkSize: chunkSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diff.Resources) != 6 {
+				t.Errorf("got %d resources, want 6", len(diff.Resources))
+			}
+			if want := decimal.NewFromInt(10); !diff.PriorCost.Equal(want) {
+				t.Errorf("PriorCost = %s, want %s", diff.PriorCost, want)
+			}
+			if want := decimal.NewFromInt(21); !diff.NewCost.Equal(want) {
+				t.Errorf("NewCost = %s, want %s", diff.NewCost, want)
+			}
+		})
+	}
+}
+
+// moduleDiff is the costs and the action of a module diff
+type moduleDiff struct {
+	priorCost, newCost string
+	action             schema.Action
+	resources          []string
+}
+
+// moduleDiffs returns the costs, the actions and the resources of the module diff and its child modules by their path
+func moduleDiffs(diff schema.ModularStateDiff, path string, diffs map[string]moduleDiff) {
+	d := moduleDiff{priorCost: diff.PriorCost.String(), newCost: diff.NewCost.String(), action: diff.Action}
+	for address, res := range diff.Resources {
+		d.resources = append(d.resources, fmt.Sprintf("%s %s %s -> %s", res.Action, address, res.PriorCost, res.NewCost))
+	}
+	sort.Strings(d.resources)
+	diffs[path] = d
+	for address, child := range diff.ChildModules {
+		moduleDiffs(child, path+"/"+address, diffs)
+	}
+}
+
+func TestGetSubmissionsDiffV2Chunked(t *testing.T) {
+	current, compareTo := testModule(), testModule()
+	// module.a.module.b has a created and a removed resource, module.c is created and module.d is removed
+	current.ChildModules[0].ChildModules[0].Resources = testResources("aws_s3_bucket", 2)
+	compareTo.ChildModules[0].ChildModules[0].Resources = testResources("aws_s3_bucket", 2)[1:]
+	compareTo.ChildModules[0].ChildModules[0].Resources = append(compareTo.ChildModules[0].ChildModules[0].Resources,
+		schema.ResourceDef{Address: "aws_s3_bucket.old", Values: map[string]interface{}{"cost": float64(10)}})
+	compareTo.ChildModules[1] = schema.ModuleDef{Address: "module.d", Resources: testResources("aws_lb", 1)}
+	req := schema.SubmissionsDiffV2{
+		Current:   schema.SubmissionV2{RootModule: current},
+		CompareTo: schema.SubmissionV2{RootModule: compareTo},
+	}
+
+	unchunked, err := GetSubmissionsDiffV2Chunked(&fakeClient{}, req, ChunkOptions{ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]moduleDiff)
+	moduleDiffs(*unchunked, "", want)
+	wantActions := map[string]schema.Action{
+		"":                            schema.ActionModify,
+		"/module.a":                   schema.ActionModify,
+		"/module.a/module.a.module.b": schema.ActionModify,
+		"/module.c":                   schema.ActionCreate,
+		"/module.d":                   schema.ActionRemove,
+	}
+	for path, action := range wantActions {
+		if want[path].action != action {
+			t.Errorf("unchunked action of %q = %s, want %s", path, want[path].action, action)
+		}
+	}
+
+	for _, chunkSize := range []int{1, 2, 3, 5} {
+		t.Run(fmt.Sprintf("chunks of %d", chunkSize), func(t *testing.T) {
+			diff, err := GetSubmissionsDiffV2Chunked(&fakeClient{}, req, ChunkOptions{ChunkSize: chunkSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !diff.PriorCost.Equal(unchunked.PriorCost) || !diff.NewCost.Equal(unchunked.NewCost) {
+				t.Errorf("costs = %s -> %s, want the unchunked %s -> %s", diff.PriorCost, diff.NewCost, unchunked.PriorCost, unchunked.NewCost)
+			}
+			got := make(map[string]moduleDiff)
+			moduleDiffs(*diff, "", got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("merged diff = %v, want the unchunked %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergeModularStateDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		actions    []schema.Action
+		wantAction schema.Action
+	}{
+		{name: "same action", actions: []schema.Action{schema.ActionCreate, schema.ActionCreate}, wantAction: schema.ActionCreate},
+		{name: "created and removed", actions: []schema.Action{schema.ActionCreate, schema.ActionRemove}, wantAction: schema.ActionModify},
+		{name: "removed and modified", actions: []schema.Action{schema.ActionRemove, schema.ActionModify}, wantAction: schema.ActionModify},
+		{name: "no action in a chunk", actions: []schema.Action{"", schema.ActionRemove}, wantAction: schema.ActionRemove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var merged schema.ModularStateDiff
+			for i, action := range tt.actions {
+				mergeModularStateDiff(&merged, schema.ModularStateDiff{
+					Resources: map[string]schema.ResourceDiff{fmt.Sprintf("aws_instance.r%d", i): {}},
+					ChildModules: map[string]schema.ModularStateDiff{"module.m": {
+						PriorCost: decimal.NewFromInt(1),
+						NewCost:   decimal.NewFromInt(2),
+						Action:    action,
+					}},
+					PriorCost: decimal.NewFromInt(1),
+					NewCost:   decimal.NewFromInt(2),
+					Action:    action,
+				})
+			}
+			if merged.Action != tt.wantAction {
+				t.Errorf("Action = %s, want %s", merged.Action, tt.wantAction)
+			}
+			if child := merged.ChildModules["module.m"]; child.Action != tt.wantAction {
+				t.Errorf("child module Action = %s, want %s", child.Action, tt.wantAction)
+			}
+			wantPrior, wantNew := decimal.NewFromInt(int64(len(tt.actions))), decimal.NewFromInt(int64(2*len(tt.actions)))
+			if !merged.PriorCost.Equal(wantPrior) || !merged.NewCost.Equal(wantNew) {
+				t.Errorf("costs = %s -> %s, want %s -> %s", merged.PriorCost, merged.NewCost, wantPrior, wantNew)
+			}
+			if len(merged.Resources) != len(tt.actions) {
+				t.Errorf("got %d resources, want %d", len(merged.Resources), len(tt.actions))
+			}
+		})
+	}
+}
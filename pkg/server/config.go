@@ -2,38 +2,105 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/api/auth0"
 	"os"
-	"strings"
+	"path/filepath"
+	"sync"
 	"time"
 )
 
+// DefaultProfile is the profile used when none is selected
+const DefaultProfile = "default"
+
+const (
+	configDir  = ".kaytu"
+	configFile = "pennywise-config.json"
+)
+
+// Config is the identity and settings of a single profile.
+// The tokens are kept in the OS keyring when it's available and only written to the config file otherwise.
 type Config struct {
-	AccessToken      string `json:"access-token"`
+	AccessToken      string `json:"access-token,omitempty"`
+	RefreshToken     string `json:"refresh-token,omitempty"`
 	DefaultWorkspace string `json:"default_workspace"`
+	ServerURL        string `json:"server_url,omitempty"`
+}
+
+// configsFile is the content of the config file.
+// The embedded Config is the single identity written by older versions, it's migrated to the default profile when read.
+type configsFile struct {
+	Config
+	Profiles map[string]Config `json:"profiles,omitempty"`
 }
 
 var ExpiredSession = fmt.Errorf("your session has expired, please login again using `pennywise login`")
 
-func getConfig() (*Config, error) {
-	home := os.Getenv("HOME")
-	data, err := os.ReadFile(home + "/.kaytu/pennywise-config.json")
+// errConfigNotFound is returned when there is no config file, nobody has logged in yet
+var errConfigNotFound = fmt.Errorf("credentials not found! please login using `pennywise login`")
+
+var activeProfile = DefaultProfile
+
+// clientCredentialsExpiryMargin is how long before its expiry the access token of the client credentials is renewed
+const clientCredentialsExpiryMargin = time.Minute
+
+// exchangeClientCredentials exchanges the client id and secret for an access token
+var exchangeClientCredentials = auth0.ClientCredentialsToken
+
+// clientCredentialsToken is the access token exchanged for the client credentials,
+// it's reused by the process until it expires
+var clientCredentialsToken struct {
+	sync.Mutex
+	clientId     string
+	clientSecret string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+// SetProfile selects the profile used to read and write the config
+func SetProfile(profile string) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	activeProfile = profile
+}
+
+// ActiveProfile returns the name of the selected profile
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// GetConfig returns the config of the active profile, or the one defined by the environment variables.
+// Expired access tokens are refreshed if there is a refresh token.
+func GetConfig() (*Config, error) {
+	envConfig, err := getEnvConfig()
 	if err != nil {
-		if strings.Contains(err.Error(), "no such file or directory") {
-			return nil, fmt.Errorf("credentials not found! please login using `pennywise login`")
-		}
-		return nil, fmt.Errorf("[CredentialsFile] : %v", err)
+		return nil, err
+	}
+	if envConfig != nil {
+		return envConfig, nil
 	}
 
-	var config Config
-	err = json.Unmarshal(data, &config)
+	configs, err := readConfigsFile()
 	if err != nil {
-		return nil, fmt.Errorf("[getConfig] : %v", err)
+		return nil, err
+	}
+	config, ok := configs.Profiles[activeProfile]
+	if !ok {
+		return nil, credentialsNotFound()
+	}
+
+	creds, err := getKeyringCredentials(activeProfile)
+	if err == nil && creds != nil {
+		config.AccessToken = creds.AccessToken
+		config.RefreshToken = creds.RefreshToken
 	}
 
 	if config.AccessToken == "" {
-		return nil, fmt.Errorf("please log in first")
+		return nil, credentialsNotFound()
 	}
 
 	checkEXP, err := CheckExpirationTime(config.AccessToken)
@@ -41,50 +108,255 @@ func getConfig() (*Config, error) {
 		return nil, fmt.Errorf("[getConfig] : %v", err)
 	}
 	if checkEXP == true {
-		return nil, ExpiredSession
+		if config.RefreshToken == "" {
+			return nil, ExpiredSession
+		}
+		token, err := auth0.RefreshAccessToken(config.RefreshToken)
+		if err != nil {
+			return nil, ExpiredSession
+		}
+		config.AccessToken = token.AccessToken
+		// refresh tokens might be rotated on each use
+		if token.RefreshToken != "" {
+			config.RefreshToken = token.RefreshToken
+		}
+		err = SetConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("[getConfig] : %v", err)
+		}
 	}
 
 	return &config, nil
 }
 
+// credentialsNotFound returns the error telling to log in to the active profile
+func credentialsNotFound() error {
+	if activeProfile != DefaultProfile {
+		return fmt.Errorf("credentials not found for profile %s! please login using `pennywise login --profile %s`", activeProfile, activeProfile)
+	}
+	return fmt.Errorf("credentials not found! please login using `pennywise login`")
+}
+
+// getEnvConfig returns the config defined by the environment variables for non-interactive environments.
+// An API key is used as it is, client id and secret are exchanged for an access token using the client credentials grant.
+// Returns nil if none of them are set.
+func getEnvConfig() (*Config, error) {
+	workspace := os.Getenv(pkg.WorkspaceEnv)
+	if apiKey := os.Getenv(pkg.APIKeyEnv); apiKey != "" {
+		return &Config{AccessToken: apiKey, DefaultWorkspace: workspace}, nil
+	}
+
+	clientId := os.Getenv(pkg.ClientIDEnv)
+	clientSecret := os.Getenv(pkg.ClientSecretEnv)
+	if clientId == "" && clientSecret == "" {
+		return nil, nil
+	}
+	if clientId == "" || clientSecret == "" {
+		return nil, fmt.Errorf("both %s and %s must be set to authenticate with client credentials", pkg.ClientIDEnv, pkg.ClientSecretEnv)
+	}
+	accessToken, err := clientCredentialsAccessToken(clientId, clientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("[getEnvConfig] : %v", err)
+	}
+	return &Config{AccessToken: accessToken, DefaultWorkspace: workspace}, nil
+}
+
+// clientCredentialsAccessToken returns the access token of the client credentials,
+// they're only exchanged again when the client changes or the token is about to expire
+func clientCredentialsAccessToken(clientId, clientSecret string) (string, error) {
+	clientCredentialsToken.Lock()
+	defer clientCredentialsToken.Unlock()
+	if clientCredentialsToken.clientId == clientId && clientCredentialsToken.clientSecret == clientSecret &&
+		time.Now().Before(clientCredentialsToken.expiresAt) {
+		return clientCredentialsToken.accessToken, nil
+	}
+
+	token, err := exchangeClientCredentials(clientId, clientSecret)
+	if err != nil {
+		return "", err
+	}
+	expiresAt := time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	if token.ExpiresIn <= 0 {
+		// the token isn't cached if its expiration isn't known
+		expiresAt, _ = TokenExpirationTime(token.AccessToken)
+	}
+	clientCredentialsToken.clientId = clientId
+	clientCredentialsToken.clientSecret = clientSecret
+	clientCredentialsToken.accessToken = token.AccessToken
+	clientCredentialsToken.expiresAt = expiresAt.Add(-clientCredentialsExpiryMargin)
+	return token.AccessToken, nil
+}
+
+// SetWorkspace sets the workspace of the active profile
+func SetWorkspace(workspace string) error {
+	if os.Getenv(pkg.APIKeyEnv) != "" || os.Getenv(pkg.ClientIDEnv) != "" || os.Getenv(pkg.ClientSecretEnv) != "" {
+		return fmt.Errorf("the workspace is defined by %s when authenticating with environment variables", pkg.WorkspaceEnv)
+	}
+	config, err := GetConfig()
+	if err != nil {
+		return err
+	}
+	config.DefaultWorkspace = workspace
+	return SetConfig(*config)
+}
+
+// ProfileWorkspace returns the workspace chosen for the active profile with `pennywise workspace use`,
+// empty if it has none so logging in again keeps the choice
+func ProfileWorkspace() string {
+	configs, err := readConfigsFile()
+	if err != nil {
+		return ""
+	}
+	return configs.Profiles[activeProfile].DefaultWorkspace
+}
+
+// ProfileServerURL returns the server address used by the active profile, empty if it uses the default server
+func ProfileServerURL() string {
+	configs, err := readConfigsFile()
+	if err != nil {
+		return ""
+	}
+	return configs.Profiles[activeProfile].ServerURL
+}
+
+// ListProfiles returns the names of the profiles in the config file
+func ListProfiles() ([]string, error) {
+	configs, err := readConfigsFile()
+	if err != nil {
+		return nil, err
+	}
+	var profiles []string
+	for name := range configs.Profiles {
+		profiles = append(profiles, name)
+	}
+	return profiles, nil
+}
+
+// RemoveConfig removes the active profile and its credentials
 func RemoveConfig() error {
-	home := os.Getenv("HOME")
-	err := os.Remove(home + "/.kaytu/pennywise-config.json")
+	configs, err := readConfigsFile()
+	if err != nil {
+		return fmt.Errorf("[removeConfig] : %v", err)
+	}
+	config, ok := configs.Profiles[activeProfile]
+	if !ok {
+		return fmt.Errorf("[removeConfig] : profile %s not found", activeProfile)
+	}
+	delete(configs.Profiles, activeProfile)
+
+	// The tokens are kept in the config file when the keyring isn't available, like on headless machines,
+	// so failing to reach the keyring only matters when the tokens are stored there
+	err = removeKeyringCredentials(activeProfile)
+	if err != nil && config.AccessToken == "" {
+		return fmt.Errorf("[removeConfig] : %v", err)
+	}
+	err = writeConfigsFile(*configs)
 	if err != nil {
 		return fmt.Errorf("[removeConfig] : %v", err)
 	}
 	return nil
 }
 
+// SetConfig stores the config as the active profile, the tokens are stored in the OS keyring
+// when it's available and in the config file otherwise
 func SetConfig(data Config) error {
-	configs, err := json.Marshal(data)
+	configs, err := readConfigsFile()
+	if errors.Is(err, errConfigNotFound) {
+		configs = &configsFile{}
+	} else if err != nil {
+		return fmt.Errorf("[addConfig] : %w", err)
+	}
+	if configs.Profiles == nil {
+		configs.Profiles = make(map[string]Config)
+	}
+
+	err = storeKeyringCredentials(activeProfile, credentials{
+		AccessToken:  data.AccessToken,
+		RefreshToken: data.RefreshToken,
+	})
+	if err == nil {
+		data.AccessToken = ""
+		data.RefreshToken = ""
+	}
+	configs.Profiles[activeProfile] = data
+
+	err = writeConfigsFile(*configs)
 	if err != nil {
 		return fmt.Errorf("[addConfig] : %v", err)
 	}
-	home := os.Getenv("HOME")
-	_, err = os.Stat(home + "/.kaytu")
+	return nil
+}
+
+func configFilePath() string {
+	return filepath.Join(os.Getenv("HOME"), configDir, configFile)
+}
+
+func readConfigsFile() (*configsFile, error) {
+	data, err := os.ReadFile(configFilePath())
 	if err != nil {
-		err = os.Mkdir(home+"/.kaytu", os.ModePerm)
-		if err != nil {
-			return fmt.Errorf("[addConfig] : %v", err)
+		if os.IsNotExist(err) {
+			return nil, errConfigNotFound
 		}
+		return nil, fmt.Errorf("[CredentialsFile] : %v", err)
 	}
 
-	err = os.WriteFile(home+"/.kaytu/pennywise-config.json", configs, os.ModePerm)
+	var configs configsFile
+	err = json.Unmarshal(data, &configs)
 	if err != nil {
-		return fmt.Errorf("[addConfig] : %v", err)
+		return nil, fmt.Errorf("[getConfig] : %v", err)
 	}
-	return nil
+
+	if configs.Config != (Config{}) {
+		if configs.Profiles == nil {
+			configs.Profiles = make(map[string]Config)
+		}
+		if _, ok := configs.Profiles[DefaultProfile]; !ok {
+			configs.Profiles[DefaultProfile] = configs.Config
+		}
+		configs.Config = Config{}
+	}
+	return &configs, nil
 }
+
+// writeConfigsFile writes the config file only readable by the user since it might contain the tokens
+func writeConfigsFile(configs configsFile) error {
+	data, err := json.Marshal(configs)
+	if err != nil {
+		return err
+	}
+	path := configFilePath()
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return err
+	}
+	// files written by older versions are readable by everyone
+	return os.Chmod(path, 0600)
+}
+
 func CheckExpirationTime(accessToken string) (bool, error) {
-	token, _, err := new(jwt.Parser).ParseUnverified(accessToken, jwt.MapClaims{})
+	tm, err := TokenExpirationTime(accessToken)
 	if err != nil {
 		return false, err
 	}
+	timeNow := time.Now()
+	if tm.Before(timeNow) {
+		return true, nil
+	} else if tm.After(timeNow) {
+		return false, nil
+	} else {
+		return true, nil
+	}
+}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return false, err
+// TokenExpirationTime returns the expiration time of the access token
+func TokenExpirationTime(accessToken string) (time.Time, error) {
+	claims, err := TokenClaims(accessToken)
+	if err != nil {
+		return time.Time{}, err
 	}
 
 	var tm time.Time
@@ -95,12 +367,19 @@ func CheckExpirationTime(accessToken string) (bool, error) {
 		v, _ := iat.Int64()
 		tm = time.Unix(v, 0)
 	}
-	timeNow := time.Now()
-	if tm.Before(timeNow) {
-		return true, nil
-	} else if tm.After(timeNow) {
-		return false, nil
-	} else {
-		return true, err
+	return tm, nil
+}
+
+// TokenClaims returns the claims of the access token without verifying it
+func TokenClaims(accessToken string) (jwt.MapClaims, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(accessToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
 	}
+	return claims, nil
 }
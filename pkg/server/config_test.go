@@ -0,0 +1,96 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kaytu-io/pennywise/pkg"
+	"github.com/kaytu-io/pennywise/pkg/api/auth0"
+)
+
+// fakeClientCredentials replaces the client credentials exchange for the test, each exchange returns a new token
+// that expires in expiresIn seconds. It returns the number of exchanges.
+func fakeClientCredentials(t *testing.T, expiresIn int) *int {
+	t.Helper()
+	exchanges := new(int)
+	exchange := exchangeClientCredentials
+	t.Cleanup(func() {
+		exchangeClientCredentials = exchange
+		clientCredentialsToken.clientId, clientCredentialsToken.clientSecret = "", ""
+	})
+	exchangeClientCredentials = func(clientId, clientSecret string) (*auth0.ResponseAccessToken, error) {
+		*exchanges++
+		if clientSecret == "wrong" {
+			return nil, errors.New("access denied")
+		}
+		return &auth0.ResponseAccessToken{AccessToken: clientId + "-token", ExpiresIn: expiresIn}, nil
+	}
+	clientCredentialsToken.clientId, clientCredentialsToken.clientSecret = "", ""
+	return exchanges
+}
+
+func TestGetConfigClientCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		expiresIn     int
+		secrets       []string
+		wantExchanges int
+	}{
+		{name: "token reused by the process", expiresIn: 3600, secrets: []string{"secret", "secret", "secret"}, wantExchanges: 1},
+		{name: "token about to expire", expiresIn: 30, secrets: []string{"secret", "secret"}, wantExchanges: 2},
+		{name: "other client secret", expiresIn: 3600, secrets: []string{"secret", "other"}, wantExchanges: 2},
+		{name: "failed exchange isn't cached", expiresIn: 3600, secrets: []string{"wrong", "wrong"}, wantExchanges: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchanges := fakeClientCredentials(t, tt.expiresIn)
+			t.Setenv(pkg.APIKeyEnv, "")
+			t.Setenv(pkg.ClientIDEnv, "client")
+			t.Setenv(pkg.WorkspaceEnv, "ws")
+			for _, secret := range tt.secrets {
+				t.Setenv(pkg.ClientSecretEnv, secret)
+				config, err := GetConfig()
+				if secret == "wrong" {
+					if err == nil {
+						t.Errorf("GetConfig() error = nil, want the exchange error")
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("GetConfig() error = %v", err)
+				}
+				if config.AccessToken != "client-token" || config.DefaultWorkspace != "ws" {
+					t.Errorf("GetConfig() = %+v, want the client token and the ws workspace", config)
+				}
+			}
+			if *exchanges != tt.wantExchanges {
+				t.Errorf("got %d exchanges, want %d", *exchanges, tt.wantExchanges)
+			}
+		})
+	}
+}
+
+func TestSetWorkspaceWithEnvConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "api key", env: map[string]string{pkg.APIKeyEnv: "key"}},
+		{name: "client credentials", env: map[string]string{pkg.ClientIDEnv: "client", pkg.ClientSecretEnv: "secret"}},
+		{name: "client secret only", env: map[string]string{pkg.ClientSecretEnv: "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchanges := fakeClientCredentials(t, 3600)
+			for _, key := range []string{pkg.APIKeyEnv, pkg.ClientIDEnv, pkg.ClientSecretEnv} {
+				t.Setenv(key, tt.env[key])
+			}
+			if err := SetWorkspace("ws"); err == nil {
+				t.Errorf("SetWorkspace() error = nil, want the workspace to be defined by %s", pkg.WorkspaceEnv)
+			}
+			if *exchanges != 0 {
+				t.Errorf("got %d exchanges, want none", *exchanges)
+			}
+		})
+	}
+}
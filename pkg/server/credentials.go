@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name the credentials are stored under in the OS keyring
+const keyringService = "pennywise"
+
+// credentials are the secrets of a profile, stored in the OS keyring when it's available
+type credentials struct {
+	AccessToken  string `json:"access-token"`
+	RefreshToken string `json:"refresh-token,omitempty"`
+}
+
+// storeKeyringCredentials stores the profile credentials in the OS keyring,
+// returns an error if there is no keyring available
+func storeKeyringCredentials(profile string, creds credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(keyringService, profile, string(data))
+}
+
+// getKeyringCredentials returns the profile credentials stored in the OS keyring, nil if there aren't any
+func getKeyringCredentials(profile string) (*credentials, error) {
+	data, err := keyring.Get(keyringService, profile)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var creds credentials
+	err = json.Unmarshal([]byte(data), &creds)
+	if err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// removeKeyringCredentials removes the profile credentials from the OS keyring
+func removeKeyringCredentials(profile string) error {
+	err := keyring.Delete(keyringService, profile)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"github.com/kaytu-io/pennywise/pkg/cost"
@@ -10,6 +11,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +19,18 @@ type EchoError struct {
 	Message string `json:"message"`
 }
 
+const (
+	// ProtocolVersion is sent on every request so the server knows the client supports POST pricing
+	// requests with gzip compressed bodies.
+	ProtocolVersion = "2.1"
+	// LegacyProtocolVersion is the protocol used by older servers which only accept pricing
+	// requests as uncompressed GET bodies.
+	LegacyProtocolVersion = "2.0"
+
+	HeaderProtocolVersion = "X-Pennywise-Protocol"
+	HeaderWorkspace       = "X-Pennywise-Workspace"
+)
+
 type ServerClient interface {
 	GetStateCost(req schema.Submission) (*cost.State, error)
 	GetStateCostV2(req schema.SubmissionV2) (*cost.ModularState, error)
@@ -26,18 +40,26 @@ type ServerClient interface {
 	ListServices(provider string) ([]string, error)
 	GetSubmissionsDiff(req schema.SubmissionsDiff) (*schema.StateDiff, error)
 	GetSubmissionsDiffV2(req schema.SubmissionsDiffV2) (*schema.ModularStateDiff, error)
+	ListWorkspaces() ([]schema.Workspace, error)
 }
 
 type serverClient struct {
 	baseURL string
 	config  *Config
+
+	// legacyProtocol is set once the server has rejected a POST pricing request,
+	// the following requests are sent using the legacy protocol directly.
+	legacyProtocol atomic.Bool
 }
 
 func NewPennywiseServerClient(baseURL string) (ServerClient, error) {
-	config, err := getConfig()
+	config, err := GetConfig()
 	if err != nil {
 		return nil, err
 	}
+	if config.ServerURL != "" {
+		baseURL = config.ServerURL
+	}
 	return &serverClient{baseURL: baseURL, config: config}, nil
 }
 
@@ -46,7 +68,7 @@ func (s *serverClient) ListServices(provider string) ([]string, error) {
 	url = strings.ReplaceAll(url, " ", "%20")
 
 	var listNewServices []string
-	if statusCode, err := s.doRequest(http.MethodGet, url, nil, &listNewServices); err != nil {
+	if statusCode, err := s.doRequest(http.MethodGet, url, nil, false, &listNewServices); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -60,7 +82,7 @@ func (s *serverClient) ListIngestionJobs(provider, service, region, status strin
 	url = strings.ReplaceAll(url, " ", "%20")
 
 	var jobs []schema.IngestionJob
-	if statusCode, err := s.doRequest(http.MethodGet, url, nil, &jobs); err != nil {
+	if statusCode, err := s.doRequest(http.MethodGet, url, nil, false, &jobs); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -76,7 +98,7 @@ func (s *serverClient) GetIngestionJob(id string) (*schema.IngestionJob, error)
 	url := fmt.Sprintf("%s/api/v1/ingestion/jobs/%s", s.baseURL, id)
 
 	var job schema.IngestionJob
-	if statusCode, err := s.doRequest(http.MethodGet, url, nil, &job); err != nil {
+	if statusCode, err := s.doRequest(http.MethodGet, url, nil, false, &job); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			if strings.Contains(err.Error(), "this ID does not exist") {
 				return nil, fmt.Errorf("this ID does not exist")
@@ -96,7 +118,7 @@ func (s *serverClient) AddIngestion(provider, service, region string) (*schema.I
 	url = strings.ReplaceAll(url, " ", "%20")
 
 	var job schema.IngestionJob
-	if statusCode, err := s.doRequest(http.MethodPut, url, nil, &job); err != nil {
+	if statusCode, err := s.doRequest(http.MethodPut, url, nil, false, &job); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			if strings.Contains(err.Error(), "this service is not supported") {
 				return nil, fmt.Errorf("this services is not supported")
@@ -111,6 +133,22 @@ func (s *serverClient) AddIngestion(provider, service, region string) (*schema.I
 	return &job, nil
 }
 
+func (s *serverClient) ListWorkspaces() ([]schema.Workspace, error) {
+	url := fmt.Sprintf("%s/api/v1/workspaces", s.baseURL)
+
+	var workspaces []schema.Workspace
+	if statusCode, err := s.doRequest(http.MethodGet, url, nil, false, &workspaces); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		if strings.Contains(err.Error(), "connect: connection refused") {
+			return nil, fmt.Errorf("Can't connect to the server. Please ensure that your server is running or that you have entered the --server-url flag currectly ")
+		}
+		return nil, err
+	}
+	return workspaces, nil
+}
+
 func (s *serverClient) GetStateCost(req schema.Submission) (*cost.State, error) {
 	url := fmt.Sprintf("%s/api/v1/cost/submission", s.baseURL)
 
@@ -119,7 +157,7 @@ func (s *serverClient) GetStateCost(req schema.Submission) (*cost.State, error)
 		return nil, err
 	}
 	var cost cost.State
-	if statusCode, err := s.doRequest(http.MethodGet, url, payload, &cost); err != nil {
+	if statusCode, err := s.doPricingRequest(url, payload, &cost); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -139,7 +177,7 @@ func (s *serverClient) GetStateCostV2(req schema.SubmissionV2) (*cost.ModularSta
 		return nil, err
 	}
 	var cost cost.ModularState
-	if statusCode, err := s.doRequest(http.MethodGet, url, payload, &cost); err != nil {
+	if statusCode, err := s.doPricingRequest(url, payload, &cost); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -159,7 +197,7 @@ func (s *serverClient) GetSubmissionsDiff(req schema.SubmissionsDiff) (*schema.S
 		return nil, err
 	}
 	var cost schema.StateDiff
-	if statusCode, err := s.doRequest(http.MethodGet, url, payload, &cost); err != nil {
+	if statusCode, err := s.doPricingRequest(url, payload, &cost); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -179,7 +217,7 @@ func (s *serverClient) GetSubmissionsDiffV2(req schema.SubmissionsDiffV2) (*sche
 		return nil, err
 	}
 	var cost schema.ModularStateDiff
-	if statusCode, err := s.doRequest(http.MethodGet, url, payload, &cost); err != nil {
+	if statusCode, err := s.doPricingRequest(url, payload, &cost); err != nil {
 		if 400 <= statusCode && statusCode < 500 {
 			return nil, echo.NewHTTPError(statusCode, err.Error())
 		}
@@ -191,13 +229,57 @@ func (s *serverClient) GetSubmissionsDiffV2(req schema.SubmissionsDiffV2) (*sche
 	return &cost, nil
 }
 
-func (s *serverClient) doRequest(method, url string, payload []byte, v interface{}) (statusCode int, err error) {
+// doPricingRequest sends a pricing payload using POST with a gzip compressed body.
+// If the server doesn't support it yet, the request is sent again as an uncompressed GET body
+// and the rest of the requests of this client use the legacy protocol.
+func (s *serverClient) doPricingRequest(url string, payload []byte, v interface{}) (statusCode int, err error) {
+	if !s.legacyProtocol.Load() {
+		statusCode, err = s.doRequest(http.MethodPost, url, payload, true, v)
+		if !isLegacyServerStatus(statusCode) {
+			return statusCode, err
+		}
+		s.legacyProtocol.Store(true)
+	}
+	return s.doRequest(http.MethodGet, url, payload, false, v)
+}
+
+// isLegacyServerStatus checks if the status code returned for a POST pricing request
+// means the server only supports the legacy protocol
+func isLegacyServerStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusUnsupportedMediaType:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *serverClient) doRequest(method, url string, payload []byte, compress bool, v interface{}) (statusCode int, err error) {
+	protocolVersion := ProtocolVersion
+	if s.legacyProtocol.Load() {
+		protocolVersion = LegacyProtocolVersion
+	}
+	if compress {
+		payload, err = gzipPayload(payload)
+		if err != nil {
+			return statusCode, fmt.Errorf("compress payload: %w", err)
+		}
+	}
+
 	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
 	if err != nil {
 		return statusCode, fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set(echo.HeaderContentType, "application/json")
 	req.Header.Set(strings.ToLower(echo.HeaderAuthorization), "Bearer "+s.config.AccessToken)
+	req.Header.Set(HeaderProtocolVersion, protocolVersion)
+	if s.config.DefaultWorkspace != "" {
+		req.Header.Set(HeaderWorkspace, s.config.DefaultWorkspace)
+	}
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	if compress {
+		req.Header.Set(echo.HeaderContentEncoding, "gzip")
+	}
 	t := http.DefaultTransport.(*http.Transport)
 	client := http.Client{
 		Timeout:   3 * time.Minute,
@@ -209,7 +291,15 @@ func (s *serverClient) doRequest(method, url string, payload []byte, v interface
 		return statusCode, fmt.Errorf("do request: %w", err)
 	}
 	defer res.Body.Close()
-	body := res.Body
+	var body io.Reader = res.Body
+	if res.Header.Get(echo.HeaderContentEncoding) == "gzip" {
+		gzipReader, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return res.StatusCode, fmt.Errorf("decompress body: %w", err)
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
 
 	statusCode = res.StatusCode
 	if res.StatusCode != http.StatusOK {
@@ -231,3 +321,16 @@ func (s *serverClient) doRequest(method, url string, payload []byte, v interface
 
 	return statusCode, json.NewDecoder(body).Decode(v)
 }
+
+// gzipPayload compresses the request payload
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,9 @@
 package usage
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 const (
 	// Key is the key used to set the usage
@@ -8,6 +11,12 @@ const (
 	Key string = "pennywise_usage"
 )
 
+// The usage keys of the reserved instances, the term and the payment option define their upfront payment
+const (
+	ReservedInstanceTermKey          = "reserved_instance_term"
+	ReservedInstancePaymentOptionKey = "reserved_instance_payment_option"
+)
+
 // Default is the default Usage that will be used if none is configured
 var Default = Usage{
 	"aws_eks_node_group": map[string]interface{}{
@@ -80,3 +89,41 @@ func (u Usage) GetUsage(rt string, addr string) map[string]interface{} {
 
 	return nil
 }
+
+const (
+	// SourceUsageFile is the source of the usage keys set in the usage file
+	SourceUsageFile = "usage file"
+	// SourcePlan is the source of the usage keys read from the resource values in the plan
+	SourcePlan = "plan value"
+)
+
+// Value is a usage key of a resource with its value and where the value comes from
+type Value struct {
+	Key    string
+	Value  interface{}
+	Source string
+}
+
+// Values returns the usage keys of a resource from its values sorted by the key.
+// The keys set in the usage file are taken from the Key value, the rest of the usage keys of the resource type in Default
+// are read from the resource values when they're set there. The keys that aren't set in either are left out since
+// they don't take part in the pricing.
+func Values(resourceType string, values map[string]interface{}) []Value {
+	var result []Value
+	fileUsage, _ := values[Key].(map[string]interface{})
+	for k, v := range fileUsage {
+		result = append(result, Value{Key: k, Value: v, Source: SourceUsageFile})
+	}
+	for k := range Default[resourceType] {
+		if _, ok := fileUsage[k]; ok {
+			continue
+		}
+		if planValue, ok := values[k]; ok && planValue != nil {
+			result = append(result, Value{Key: k, Value: planValue, Source: SourcePlan})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}